@@ -2,31 +2,54 @@ package main
 
 import (
 	"example_http_server/handlers/users"
+	"example_http_server/store"
 	"github.com/AlhimicMan/goswag/generator"
 	"github.com/AlhimicMan/goswag/wrapper"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
-	echoSwagger "github.com/swaggo/echo-swagger"
+	"net/http"
 )
 
 func main() {
 	e := echo.New()
 	e.Logger.SetLevel(log.INFO)
-	router := wrapper.NewRouter(e)
-	group := router.Group("/users", "Users")
+	router := wrapper.NewRouter(e, generator.SpecOptions{
+		Title:       "Portal API",
+		Description: "Example users service",
+		Version:     "1.0",
+	})
+	router.SetErrorRegistry(errorRegistry())
+	router.SetDefaultResponses(generator.ResponseParameters{
+		Status:      http.StatusInternalServerError,
+		Description: "Internal error",
+		Body:        wrapper.ProblemDetails{},
+	})
+	group := router.GroupWithTag("/users", generator.TagInfo{Name: "Users", Description: "Users and avatars management"})
 	RegisterRoutes(group)
 	for _, route := range e.Routes() {
 		e.Logger.Infof("registered %s: %s %s", route.Method, route.Path, route.Name)
 	}
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
-	_, err := router.GenerateSwagger()
+	err := router.MountDocs(wrapper.DocsOptions{Path: "/swagger"})
 	if err != nil {
-		e.Logger.Fatalf("cannot generate swagger: %w", err)
+		e.Logger.Fatalf("cannot mount docs: %v", err)
 	}
 
 	e.Logger.Fatal(e.Start(":1323"))
 }
 
+func errorRegistry() *wrapper.ErrorRegistry {
+	return wrapper.NewErrorRegistry().
+		Register(store.ErrUserNotFound, wrapper.ErrorMapping{
+			Status: http.StatusNotFound, Code: "user_not_found", Message: "user not found",
+		}).
+		Register(store.ErrLoginDuplicated, wrapper.ErrorMapping{
+			Status: http.StatusConflict, Code: "login_duplicated", Message: "login already taken",
+		}).
+		Register(store.ErrAvatarNotFound, wrapper.ErrorMapping{
+			Status: http.StatusNotFound, Code: "avatar_not_found", Message: "avatar not found",
+		})
+}
+
 func RegisterRoutes(group *wrapper.WrapGroup) {
 	authParams := generator.AuthType{
 		AuthTypeName: "API Key",
@@ -34,33 +57,43 @@ func RegisterRoutes(group *wrapper.WrapGroup) {
 		APIKey:       &generator.APIKeyParams{In: "header", Name: "X-API-Key"},
 	}
 	hAuth := []generator.AuthType{authParams}
+	notFound := generator.ResponseParameters{
+		Status:      http.StatusNotFound,
+		Description: "User not found",
+		Body:        wrapper.ProblemDetails{},
+	}
 	group.GET("/:id", generator.HandlerParameters{
-		Summary: "Get user",
-		Auth:    hAuth,
+		Summary:   "Get user",
+		Auth:      hAuth,
+		Responses: []generator.ResponseParameters{notFound},
 	}, users.GetUser)
 	group.GET("/:id/avatar", generator.HandlerParameters{
-		Summary: "Get user avatar",
-		Auth:    hAuth,
+		Summary:       "Get user avatar",
+		Auth:          hAuth,
+		SuccessStatus: http.StatusOK,
 	}, users.GetUserAvatar)
 	group.GET("/list", generator.HandlerParameters{
 		Summary: "List users",
 	}, users.ListUsers)
 	additionalFilesUploadParams := generator.FileUploadParameters{Name: "custom_file", MultipleFiles: false}
 	group.POST("/create", generator.HandlerParameters{
-		Summary:    "Create user",
-		Auth:       hAuth,
-		FileUpload: []generator.FileUploadParameters{additionalFilesUploadParams},
+		Summary:       "Create user",
+		Auth:          hAuth,
+		FileUpload:    []generator.FileUploadParameters{additionalFilesUploadParams},
+		SuccessStatus: http.StatusCreated,
 	}, users.CreateUser)
 	group.POST("/:id", generator.HandlerParameters{
-		Summary: "Update user",
-		Auth:    hAuth,
+		Summary:   "Update user",
+		Auth:      hAuth,
+		Responses: []generator.ResponseParameters{notFound},
 	}, users.UpdateUser)
 	group.POST("/:id/avatar", generator.HandlerParameters{
 		Summary: "Update user avatar",
 		Auth:    hAuth,
 	}, users.UpdateUserAvatar)
 	group.DELETE("/:id", generator.HandlerParameters{
-		Summary: "Delete user",
-		Auth:    hAuth,
+		Summary:       "Delete user",
+		Auth:          hAuth,
+		SuccessStatus: http.StatusNoContent,
 	}, users.DeleteUser)
 }
@@ -6,6 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrLoginDuplicated = errors.New("login duplicated")
+	ErrAvatarNotFound  = errors.New("avatar for user not defined")
+)
+
 var usersStorage map[string]models.UserRec
 var loginToID map[string]string
 var avatarStorage map[string]models.Avatar
@@ -19,7 +25,7 @@ func init() {
 func CreateUser(user *models.UserRec) error {
 	_, ok := loginToID[user.Login]
 	if ok {
-		return errors.New("login duplicated")
+		return ErrLoginDuplicated
 	}
 	id := uuid.New().String()
 	user.ID = id
@@ -31,7 +37,7 @@ func CreateUser(user *models.UserRec) error {
 func GetUserByID(id string) (models.UserRec, error) {
 	user, ok := usersStorage[id]
 	if !ok {
-		return models.UserRec{}, errors.New("user not found")
+		return models.UserRec{}, ErrUserNotFound
 	}
 	return user, nil
 }
@@ -39,7 +45,7 @@ func GetUserByID(id string) (models.UserRec, error) {
 func UpdateUser(user models.UserRec) error {
 	_, ok := usersStorage[user.ID]
 	if !ok {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 	usersStorage[user.ID] = user
 	return nil
@@ -56,7 +62,7 @@ func ListUses() []models.UserRec {
 func DeleteUser(id string) error {
 	usr, ok := usersStorage[id]
 	if !ok {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 	delete(usersStorage, id)
 	delete(loginToID, usr.Login)
@@ -71,7 +77,7 @@ func SaveAvatarByUserID(id string, avatar models.Avatar) error {
 func GetAvatarByUserID(id string) (models.Avatar, error) {
 	avatar, ok := avatarStorage[id]
 	if !ok {
-		return models.Avatar{}, errors.New("avatar for user not defined")
+		return models.Avatar{}, ErrAvatarNotFound
 	}
 	return avatar, nil
 }
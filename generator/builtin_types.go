@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+var (
+	rawMessageType     = reflect.TypeOf(json.RawMessage{})
+	jsonNumberType     = reflect.TypeOf(json.Number(""))
+	ipType             = reflect.TypeOf(net.IP{})
+	urlType            = reflect.TypeOf(url.URL{})
+	addrType           = reflect.TypeOf(netip.Addr{})
+	addrPortType       = reflect.TypeOf(netip.AddrPort{})
+	prefixType         = reflect.TypeOf(netip.Prefix{})
+	bigIntType         = reflect.TypeOf(big.Int{})
+	bigFloatType       = reflect.TypeOf(big.Float{})
+	bigRatType         = reflect.TypeOf(big.Rat{})
+	jsonMarshalerIface = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerIface = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// builtinSchema returns schema of standard library type whose JSON encoding differs from its Go structure.
+// Other types implementing json.Marshaler or encoding.TextMarshaler are described as strings
+func builtinSchema(t reflect.Type) *openapi.Schema {
+	if t.Kind() == reflect.Ptr {
+		return nil
+	}
+	switch t {
+	case rawMessageType:
+		schema := &openapi.Schema{}
+		schema.Type = []string{"object"}
+		schema.AdditionalProperties = &openapi.SchemaOrBool{Allows: true}
+		return schema
+	case jsonNumberType:
+		return &openapi.Schema{SchemaProps: openapi.SchemaProps{Type: []string{"number"}}}
+	case durationType:
+		schema := openapi.Int64Property()
+		schema.Description = "Duration in nanoseconds"
+		return schema
+	case ipType, addrType:
+		return openapi.StrFmtProperty("ip")
+	case addrPortType:
+		return openapi.StrFmtProperty("ip-port")
+	case prefixType:
+		return openapi.StrFmtProperty("cidr")
+	case urlType:
+		return openapi.StrFmtProperty("uri")
+	case bigIntType:
+		return &openapi.Schema{SchemaProps: openapi.SchemaProps{Type: []string{"integer"}}}
+	case bigFloatType:
+		return openapi.StrFmtProperty("decimal")
+	case bigRatType:
+		return openapi.StrFmtProperty("rational")
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !isMarshaler(t.Elem()) {
+		// Byte slices are encoded as base64 strings
+		return openapi.StrFmtProperty("byte")
+	}
+	if isMarshaler(t) {
+		return openapi.StringProperty()
+	}
+	return nil
+}
+
+// isMarshaler reports if type or pointer to it has custom JSON or text encoding
+func isMarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	ptrType := reflect.PtrTo(t)
+	return t.Implements(jsonMarshalerIface) || t.Implements(textMarshalerIface) ||
+		ptrType.Implements(jsonMarshalerIface) || ptrType.Implements(textMarshalerIface)
+}
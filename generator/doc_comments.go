@@ -0,0 +1,224 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+// DocComments holds Go doc comments of functions, types and struct fields found by source analysis.
+// Functions are keyed by runtime names, like github.com/org/api.GetUser or github.com/org/api.(*Server).GetUser
+type DocComments struct {
+	funcs  map[string]string
+	types  map[string]string
+	fields map[string]map[string]string
+	// enums holds values of constants by their named type
+	enums      map[string][]interface{}
+	enumConsts map[string]struct{}
+}
+
+// NewDocComments returns empty doc comments, packages sources are added by AddPackage.
+// Package docload loads packages matching patterns, like ./...
+func NewDocComments() *DocComments {
+	return &DocComments{
+		funcs:      make(map[string]string),
+		types:      make(map[string]string),
+		fields:     make(map[string]map[string]string),
+		enums:      make(map[string][]interface{}),
+		enumConsts: make(map[string]struct{}),
+	}
+}
+
+// AddPackage parses source files of package with import path, like github.com/org/api/handlers.
+// Values of constants of named types are collected as their enums
+func (d *DocComments) AddPackage(pkgPath string, fileNames ...string) error {
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		file, err := parser.ParseFile(fset, fileName, nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("cannot parse %s: %w", fileName, err)
+		}
+		files = append(files, file)
+		d.addFile(pkgPath, file)
+	}
+	d.addEnums(fset, pkgPath, files)
+	return nil
+}
+
+func (d *DocComments) addFile(pkgPath string, file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if text := commentText(decl.Doc); text != "" {
+				d.funcs[pkgPath+"."+funcName(decl)] = text
+			}
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				key := pkgPath + "." + typeSpec.Name.Name
+				doc := typeSpec.Doc
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+				if text := commentText(doc); text != "" {
+					d.types[key] = text
+				}
+				if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+					d.addFields(key, structType)
+				}
+			}
+		}
+	}
+}
+
+func (d *DocComments) addFields(typeKey string, structType *ast.StructType) {
+	for _, field := range structType.Fields.List {
+		text := commentText(field.Doc)
+		if text == "" {
+			text = commentText(field.Comment)
+		}
+		if text == "" {
+			continue
+		}
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		if len(names) == 0 {
+			// Embedded field is named by type
+			names = append(names, embeddedFieldName(field.Type))
+		}
+		if d.fields[typeKey] == nil {
+			d.fields[typeKey] = make(map[string]string)
+		}
+		for _, name := range names {
+			d.fields[typeKey][name] = text
+		}
+	}
+}
+
+// funcName returns function name in form used by runtime: Func, T.Method or (*T).Method
+func funcName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+	recv := decl.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		return "(*" + receiverName(star.X) + ")." + decl.Name.Name
+	}
+	return receiverName(recv) + "." + decl.Name.Name
+}
+
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.IndexExpr:
+		return receiverName(expr.X) + "[...]"
+	case *ast.IndexListExpr:
+		return receiverName(expr.X) + "[...]"
+	}
+	return ""
+}
+
+func embeddedFieldName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return embeddedFieldName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedFieldName(expr.X)
+	case *ast.IndexListExpr:
+		return embeddedFieldName(expr.X)
+	}
+	return ""
+}
+
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.TrimSpace(group.Text())
+}
+
+// Func returns doc comment of function with runtime name, like returned by runtime.FuncForPC.
+// Method values names are resolved to methods
+func (d *DocComments) Func(name string) string {
+	if d == nil {
+		return ""
+	}
+	return d.funcs[strings.TrimSuffix(name, "-fm")]
+}
+
+// Type returns doc comment of named type
+func (d *DocComments) Type(t reflect.Type) string {
+	if d == nil {
+		return ""
+	}
+	return d.types[docTypeKey(t)]
+}
+
+// Field returns doc comment of struct field
+func (d *DocComments) Field(t reflect.Type, field reflect.StructField) string {
+	if d == nil {
+		return ""
+	}
+	// Promoted fields are documented in embedded struct
+	for _, x := range field.Index[:len(field.Index)-1] {
+		t = t.Field(x).Type
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	return d.fields[docTypeKey(t)][field.Name]
+}
+
+// docTypeKey returns key of type declaration. Type arguments of generic types are omitted
+func docTypeKey(t reflect.Type) string {
+	name, _, _ := strings.Cut(t.Name(), "[")
+	return t.PkgPath() + "." + name
+}
+
+// SetDocComments sets doc comments used for operations, definitions and properties descriptions.
+// Descriptions set by handler parameters and doc tags take precedence
+func (s *SwaggerGenerator) SetDocComments(docs *DocComments) {
+	s.docs = docs
+}
+
+// docSummary returns first sentence of doc comment
+func docSummary(doc string) string {
+	paragraph, _, _ := strings.Cut(doc, "\n\n")
+	summary := strings.Join(strings.Fields(paragraph), " ")
+	if idx := strings.Index(summary, ". "); idx >= 0 {
+		summary = summary[:idx+1]
+	}
+	return summary
+}
+
+// applyOperationDocs sets operation summary and description from handler doc comment
+// unless they are set by handler parameters
+func (s *SwaggerGenerator) applyOperationDocs(op *openapi.Operation, routeInfo RouteInfo) {
+	op.Description = routeInfo.Parameters.Description
+	doc := s.docs.Func(routeInfo.Handler.FuncName)
+	if doc == "" {
+		return
+	}
+	if op.Summary == "" {
+		op.Summary = docSummary(doc)
+	}
+	if op.Description == "" && op.Summary != doc {
+		op.Description = doc
+	}
+}
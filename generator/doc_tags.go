@@ -0,0 +1,261 @@
+package generator
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/AlhimicMan/goswag/generator/openapi3"
+	openapi "github.com/go-openapi/spec"
+)
+
+// fieldDocs is field documentation set by struct tags, like
+// `doc:"Name of user" example:"Bob" enum:"admin,user" format:"email" deprecated:"true" readOnly:"true"`.
+// Limits are set by minimum, maximum, multipleOf, minLength, maxLength, pattern, minItems and maxItems tags
+type fieldDocs struct {
+	description string
+	example     *string
+	defaultVal  *string
+	enum        []string
+	format      string
+	deprecated  bool
+	readOnly    bool
+	writeOnly   bool
+	validations openapi.CommonValidations
+}
+
+// parseDocTags reads documentation tags of field
+func parseDocTags(field reflect.StructField) fieldDocs {
+	tag := field.Tag
+	docs := fieldDocs{
+		description: tag.Get("doc"),
+		format:      tag.Get("format"),
+		deprecated:  tagBool(tag, "deprecated"),
+		readOnly:    tagBool(tag, "readOnly"),
+		writeOnly:   tagBool(tag, "writeOnly"),
+	}
+	if example, ok := tag.Lookup("example"); ok {
+		docs.example = &example
+	}
+	if defaultVal, ok := tag.Lookup("default"); ok {
+		docs.defaultVal = &defaultVal
+	}
+	if enum := tag.Get("enum"); enum != "" {
+		for _, val := range strings.Split(enum, ",") {
+			docs.enum = append(docs.enum, strings.TrimSpace(val))
+		}
+	}
+	v := &docs.validations
+	v.Minimum = tagFloat(tag, "minimum")
+	v.Maximum = tagFloat(tag, "maximum")
+	v.MultipleOf = tagFloat(tag, "multipleOf")
+	v.MinLength = tagInt(tag, "minLength")
+	v.MaxLength = tagInt(tag, "maxLength")
+	v.MinItems = tagInt(tag, "minItems")
+	v.MaxItems = tagInt(tag, "maxItems")
+	v.Pattern = tag.Get("pattern")
+	return docs
+}
+
+func tagBool(tag reflect.StructTag, key string) bool {
+	val, err := strconv.ParseBool(tag.Get(key))
+	return err == nil && val
+}
+
+func tagFloat(tag reflect.StructTag, key string) *float64 {
+	val, err := strconv.ParseFloat(tag.Get(key), 64)
+	if err != nil {
+		return nil
+	}
+	return &val
+}
+
+func tagInt(tag reflect.StructTag, key string) *int64 {
+	val, err := strconv.ParseInt(tag.Get(key), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &val
+}
+
+// tagValue converts tag value to type of schema. Arrays and objects are parsed from JSON,
+// arrays also from comma separated items
+func tagValue(val string, typeName string, itemTypeName string) interface{} {
+	switch typeName {
+	case "boolean":
+		if bVal, err := strconv.ParseBool(val); err == nil {
+			return bVal
+		}
+		return val
+	case "array", "object":
+		var jsonVal interface{}
+		if err := json.Unmarshal([]byte(val), &jsonVal); err == nil {
+			return jsonVal
+		}
+		if typeName == "object" {
+			return val
+		}
+		items := make([]interface{}, 0)
+		for _, item := range strings.Split(val, ",") {
+			items = append(items, tagValue(strings.TrimSpace(item), itemTypeName, ""))
+		}
+		return items
+	}
+	return enumValue(val, typeName)
+}
+
+func (d fieldDocs) hasAnnotations() bool {
+	return d.description != "" || d.example != nil || d.deprecated || d.readOnly || d.writeOnly
+}
+
+// applyToSchema sets documentation to field schema. Schema referring definition is wrapped to allOf,
+// because siblings of $ref are ignored
+func (d fieldDocs) applyToSchema(schema *openapi.Schema) {
+	if schema.Ref.String() != "" {
+		if !d.hasAnnotations() {
+			return
+		}
+		*schema = openapi.Schema{SchemaProps: openapi.SchemaProps{AllOf: []openapi.Schema{*schema}}}
+	}
+	var typeName, itemTypeName string
+	if len(schema.Type) > 0 {
+		typeName = schema.Type[0]
+	}
+	var items *openapi.Schema
+	if schema.Items != nil && schema.Items.Schema != nil && schema.Items.Schema.Ref.String() == "" {
+		items = schema.Items.Schema
+		if len(items.Type) > 0 {
+			itemTypeName = items.Type[0]
+		}
+	}
+	if d.description != "" {
+		schema.Description = d.description
+	}
+	if d.example != nil {
+		schema.Example = tagValue(*d.example, typeName, itemTypeName)
+	}
+	if d.defaultVal != nil {
+		schema.Default = tagValue(*d.defaultVal, typeName, itemTypeName)
+	}
+	if d.format != "" {
+		schema.Format = d.format
+	}
+	if len(d.enum) > 0 {
+		// Enum of array field lists allowed items
+		enumSchema, enumType := schema, typeName
+		if typeName == "array" && items != nil {
+			// Items schema can be shared between fields, enum is set to copy
+			itemsCopy := *items
+			schema.Items = &openapi.SchemaOrArray{Schema: &itemsCopy}
+			enumSchema, enumType = &itemsCopy, itemTypeName
+		}
+		enumSchema.Enum = make([]interface{}, 0, len(d.enum))
+		for _, val := range d.enum {
+			enumSchema.Enum = append(enumSchema.Enum, tagValue(val, enumType, ""))
+		}
+	}
+	if d.readOnly {
+		schema.ReadOnly = true
+	}
+	if d.deprecated || d.writeOnly {
+		extensions := make(openapi.Extensions, len(schema.Extensions)+2)
+		for key, val := range schema.Extensions {
+			extensions[key] = val
+		}
+		if d.deprecated {
+			extensions.Add(openapi3.DeprecatedExtension, true)
+		}
+		if d.writeOnly {
+			extensions.Add(openapi3.WriteOnlyExtension, true)
+		}
+		schema.Extensions = extensions
+	}
+	v := d.validations
+	if v.Minimum != nil {
+		schema.Minimum = v.Minimum
+	}
+	if v.Maximum != nil {
+		schema.Maximum = v.Maximum
+	}
+	if v.MultipleOf != nil {
+		schema.MultipleOf = v.MultipleOf
+	}
+	if v.MinLength != nil {
+		schema.MinLength = v.MinLength
+	}
+	if v.MaxLength != nil {
+		schema.MaxLength = v.MaxLength
+	}
+	if v.MinItems != nil {
+		schema.MinItems = v.MinItems
+	}
+	if v.MaxItems != nil {
+		schema.MaxItems = v.MaxItems
+	}
+	if v.Pattern != "" {
+		schema.Pattern = v.Pattern
+	}
+}
+
+// applyToParam sets documentation to parameter. readOnly and writeOnly are not applicable to parameters
+func (d fieldDocs) applyToParam(param *openapi.Parameter) {
+	var itemTypeName string
+	if param.Items != nil {
+		itemTypeName = param.Items.Type
+	}
+	if d.description != "" {
+		param.Description = d.description
+	}
+	if d.example != nil {
+		param.Example = tagValue(*d.example, param.Type, itemTypeName)
+	}
+	if d.defaultVal != nil {
+		param.Default = tagValue(*d.defaultVal, param.Type, itemTypeName)
+	}
+	if d.format != "" {
+		param.Format = d.format
+	}
+	if len(d.enum) > 0 {
+		enum := make([]interface{}, 0, len(d.enum))
+		if param.Type == "array" && param.Items != nil {
+			for _, val := range d.enum {
+				enum = append(enum, tagValue(val, itemTypeName, ""))
+			}
+			param.Items.Enum = enum
+		} else {
+			for _, val := range d.enum {
+				enum = append(enum, tagValue(val, param.Type, ""))
+			}
+			param.Enum = enum
+		}
+	}
+	if d.deprecated {
+		param.AddExtension(openapi3.DeprecatedExtension, true)
+	}
+	v := d.validations
+	if v.Minimum != nil {
+		param.Minimum = v.Minimum
+	}
+	if v.Maximum != nil {
+		param.Maximum = v.Maximum
+	}
+	if v.MultipleOf != nil {
+		param.MultipleOf = v.MultipleOf
+	}
+	if v.MinLength != nil {
+		param.MinLength = v.MinLength
+	}
+	if v.MaxLength != nil {
+		param.MaxLength = v.MaxLength
+	}
+	if v.MinItems != nil {
+		param.MinItems = v.MinItems
+	}
+	if v.MaxItems != nil {
+		param.MaxItems = v.MaxItems
+	}
+	if v.Pattern != "" {
+		param.Pattern = v.Pattern
+	}
+}
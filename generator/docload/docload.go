@@ -0,0 +1,31 @@
+// Package docload loads Go doc comments of packages sources for spec generation. It is separate from generator,
+// so applications which do not load sources do not depend on golang.org/x/tools
+package docload
+
+import (
+	"fmt"
+
+	"github.com/AlhimicMan/goswag/generator"
+	"golang.org/x/tools/go/packages"
+)
+
+// Load parses sources of packages matching patterns, like ./... or github.com/org/api/handlers.
+// Test files are skipped. Sources must be available, so doc comments are usually loaded
+// by spec generation command run in module root
+func Load(patterns ...string) (*generator.DocComments, error) {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load packages: %w", err)
+	}
+	docs := generator.NewDocComments()
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("cannot load package %s: %w", pkg.PkgPath, pkg.Errors[0])
+		}
+		if err := docs.AddPackage(pkg.PkgPath, pkg.GoFiles...); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,22 @@
+package docload
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	docs, err := Load("github.com/AlhimicMan/goswag/generator")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Union describes implementations of interface type distinguished by discriminator property of JSON object",
+		docs.Type(reflect.TypeOf(generator.Union{})))
+	assert.Equal(t, []interface{}{int64(0), int64(1), int64(2)}, docs.Enum(reflect.TypeOf(generator.RequiredValidated)))
+
+	_, err = Load("github.com/AlhimicMan/goswag/missing")
+	assert.Error(t, err)
+}
@@ -5,41 +5,107 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/AlhimicMan/goswag/generator/openapi3"
 	openapi "github.com/go-openapi/spec"
 )
 
+// Spec versions supported by generator
+const (
+	Swagger20 = "2.0"
+	OpenAPI30 = openapi3.Version30
+	OpenAPI31 = openapi3.Version31
+)
+
 type SwaggerGenerator struct {
-	authTypes            map[string]AuthType
-	defSkipFields        map[string][]string
-	processedDefinitions map[string]struct{}
-	definitionTypes      map[string]reflect.Type
+	authTypes       map[string]AuthType
+	defSkipFields   map[reflect.Type][]string
+	definitionTypes map[reflect.Type]struct{}
+	// definitionQueue holds definition types in order they were referenced
+	definitionQueue []reflect.Type
+	names           *definitionNames
+	namer           DefinitionNamer
+	types           *TypeRegistry
+	errorCodes      map[string]ErrorCode
+	options         SpecOptions
+	requiredPolicy  RequiredPolicy
+	int64AsString   bool
+	embedsAllOf     bool
+	docs            *DocComments
+	// openAPI3 is set while OpenAPI 3 document is generated, constructs missing in Swagger 2.0 are allowed
+	openAPI3 bool
+	// resolving holds types which schemas are being generated, used to detect recursive types
+	resolving map[reflect.Type]bool
 }
 
 var timeType = reflect.TypeOf(&time.Time{}).Elem()
 
 func NewSwaggerGenerator() *SwaggerGenerator {
 	return &SwaggerGenerator{
-		defSkipFields:        make(map[string][]string),
-		authTypes:            make(map[string]AuthType),
-		processedDefinitions: make(map[string]struct{}),
-		definitionTypes:      make(map[string]reflect.Type),
+		defSkipFields:   make(map[reflect.Type][]string),
+		authTypes:       make(map[string]AuthType),
+		definitionTypes: make(map[reflect.Type]struct{}),
+		names:           newDefinitionNames(nil),
+		resolving:       make(map[reflect.Type]bool),
+		errorCodes:      make(map[string]ErrorCode),
+		types:           NewTypeRegistry(),
+	}
+}
+
+// SetSpecOptions sets spec metadata like title, version and tags descriptions
+func (s *SwaggerGenerator) SetSpecOptions(options SpecOptions) {
+	s.options = options
+}
+
+// AddErrorCodes adds error codes to catalogue exported to spec as x-error-codes extension
+func (s *SwaggerGenerator) AddErrorCodes(codes ...ErrorCode) {
+	for _, code := range codes {
+		s.errorCodes[code.Code] = code
 	}
 }
 
 func (s *SwaggerGenerator) EmitOpenAPIDefinition(routesMap map[string]RouteInfo) (openapi.Swagger, error) {
+	// Names of colliding definitions depend on all definitions types, so spec is generated twice:
+	// to collect definitions types and with names assigned to all of them
+	s.resetDefinitions()
+	if _, err := s.emitOpenAPIDefinition(routesMap); err != nil {
+		return openapi.Swagger{}, err
+	}
+	definitionTypes := s.definitionQueue
+	s.resetDefinitions()
+	s.names.assign(definitionTypes)
+	return s.emitOpenAPIDefinition(routesMap)
+}
+
+// resetDefinitions clears definitions and their names collected by spec generation
+func (s *SwaggerGenerator) resetDefinitions() {
+	s.defSkipFields = make(map[reflect.Type][]string)
+	s.definitionTypes = make(map[reflect.Type]struct{})
+	s.definitionQueue = nil
+	s.resolving = make(map[reflect.Type]bool)
+	s.names = newDefinitionNames(s.namer)
+}
+
+func (s *SwaggerGenerator) emitOpenAPIDefinition(routesMap map[string]RouteInfo) (openapi.Swagger, error) {
 	sw := openapi.Swagger{}
-	sw.Swagger = "2.0"
-	sw.Info = &openapi.Info{}
-	sw.Info.Version = "1.0"
+	sw.Swagger = Swagger20
+	s.applySpecOptions(&sw)
 	sw.Paths = &openapi.Paths{
 		Paths: make(map[string]openapi.PathItem),
 	}
 	sw.Definitions = make(map[string]openapi.Schema)
 
-	for path, routeInfo := range routesMap {
+	// Routes are processed in sorted order to generate the same spec on every run
+	routePaths := make([]string, 0, len(routesMap))
+	for path := range routesMap {
+		routePaths = append(routePaths, path)
+	}
+	sort.Strings(routePaths)
+	for _, path := range routePaths {
+		routeInfo := routesMap[path]
 		sParts := strings.Split(path, "~")
 		if len(sParts) != 2 {
 			return openapi.Swagger{}, fmt.Errorf("invalid path: %s", path)
@@ -117,11 +183,107 @@ func (s *SwaggerGenerator) EmitOpenAPIDefinition(routesMap map[string]RouteInfo)
 		return openapi.Swagger{}, fmt.Errorf("cannot process security definition: %w", err)
 	}
 	sw.SecurityDefinitions = secDefs
-	sw.Definitions = s.processDefinitions(s.definitionTypes)
+	sw.Definitions = s.processDefinitions()
+	if len(s.errorCodes) > 0 {
+		sw.AddExtension("x-error-codes", s.errorCodesCatalogue())
+	}
 
 	return sw, nil
 }
 
+// EmitOpenAPI3Definition generates OpenAPI 3 document of given version, OpenAPI30 or OpenAPI31.
+// Document is built from the same routes as Swagger 2.0 spec, definitions become components/schemas
+func (s *SwaggerGenerator) EmitOpenAPI3Definition(routesMap map[string]RouteInfo, version string) (*openapi3.Document, error) {
+	s.openAPI3 = true
+	sw, err := s.EmitOpenAPIDefinition(routesMap)
+	s.openAPI3 = false
+	if err != nil {
+		return nil, err
+	}
+	doc, err := openapi3.FromSwagger(sw, version)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert spec to OpenAPI %s: %w", version, err)
+	}
+	if len(s.options.Servers) > 0 {
+		doc.Servers = make([]openapi3.Server, 0, len(s.options.Servers))
+		for _, server := range s.options.Servers {
+			doc.Servers = append(doc.Servers, openapi3.Server{
+				URL:         server.URL,
+				Description: server.Description,
+			})
+		}
+	}
+	return doc, nil
+}
+
+// applySpecOptions sets spec info, location, external docs and tags from generator options
+func (s *SwaggerGenerator) applySpecOptions(sw *openapi.Swagger) {
+	options := s.options
+	sw.Info = &openapi.Info{}
+	sw.Info.Title = options.Title
+	if sw.Info.Title == "" {
+		sw.Info.Title = "API"
+	}
+	sw.Info.Version = options.Version
+	if sw.Info.Version == "" {
+		sw.Info.Version = "1.0"
+	}
+	sw.Info.Description = options.Description
+	sw.Info.TermsOfService = options.TermsOfService
+	if options.Contact != nil {
+		sw.Info.Contact = &openapi.ContactInfo{
+			ContactInfoProps: openapi.ContactInfoProps{
+				Name:  options.Contact.Name,
+				URL:   options.Contact.URL,
+				Email: options.Contact.Email,
+			},
+		}
+	}
+	if options.License != nil {
+		sw.Info.License = &openapi.License{
+			LicenseProps: openapi.LicenseProps{
+				Name: options.License.Name,
+				URL:  options.License.URL,
+			},
+		}
+	}
+	sw.Host = options.Host
+	sw.BasePath = options.BasePath
+	sw.Schemes = options.Schemes
+	sw.ExternalDocs = externalDocs(options.ExternalDocs)
+	for _, tag := range options.Tags {
+		sw.Tags = append(sw.Tags, openapi.Tag{
+			TagProps: openapi.TagProps{
+				Name:         tag.Name,
+				Description:  tag.Description,
+				ExternalDocs: externalDocs(tag.ExternalDocs),
+			},
+		})
+	}
+}
+
+func externalDocs(docs *ExternalDocs) *openapi.ExternalDocumentation {
+	if docs == nil {
+		return nil
+	}
+	return &openapi.ExternalDocumentation{
+		Description: docs.Description,
+		URL:         docs.URL,
+	}
+}
+
+// errorCodesCatalogue returns error codes sorted by code
+func (s *SwaggerGenerator) errorCodesCatalogue() []ErrorCode {
+	codes := make([]ErrorCode, 0, len(s.errorCodes))
+	for _, code := range s.errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+	return codes
+}
+
 func (s *SwaggerGenerator) processSecurityDefinitions() (openapi.SecurityDefinitions, error) {
 	secDefs := openapi.SecurityDefinitions{}
 	for _, aType := range s.authTypes {
@@ -179,7 +341,8 @@ func (s *SwaggerGenerator) processQueryParams(path string, routeInfo RouteInfo)
 	op := &openapi.Operation{}
 	op.Tags = append(op.Tags, routeInfo.Tags...)
 	op.Summary = routeInfo.Parameters.Summary
-	sPath := s.pathParamsProcessor(op, path)
+	s.applyOperationDocs(op, routeInfo)
+	sPath := s.pathParamsProcessor(op, path, routeInfo.Handler.RequestType)
 	skipParams := make(map[string]struct{})
 	for _, pParam := range op.Parameters {
 		skipParams[pParam.Name] = struct{}{}
@@ -189,9 +352,9 @@ func (s *SwaggerGenerator) processQueryParams(path string, routeInfo RouteInfo)
 		s.queryParamsProcessor(op, *paramType, skipParams)
 	}
 	s.processAuthParams(op, routeInfo.Parameters)
-	if routeInfo.Handler.OutputType != nil {
-		s.responseProcessor(op, *routeInfo.Handler.OutputType)
-	}
+	s.responseProcessor(op, routeInfo)
+	s.validationResponseProcessor(op, routeInfo.Handler)
+	s.declaredResponsesProcessor(op, routeInfo.Parameters.Responses)
 
 	return sPath, op
 }
@@ -200,178 +363,229 @@ func (s *SwaggerGenerator) processBodyParams(path string, routeInfo RouteInfo) (
 	op := &openapi.Operation{}
 	op.Tags = append(op.Tags, routeInfo.Tags...)
 	op.Summary = routeInfo.Parameters.Summary
-	sPath := s.pathParamsProcessor(op, path)
+	s.applyOperationDocs(op, routeInfo)
+	sPath := s.pathParamsProcessor(op, path, routeInfo.Handler.RequestType)
 	if routeInfo.Handler.RequestType != nil {
 		reqType := *routeInfo.Handler.RequestType
-		s.queryParamsOnlyProcessor(op, reqType)
-		skipParams := make([]string, 0, len(op.Parameters))
+		skipParams := s.queryParamsOnlyProcessor(op, reqType)
 		for _, pParam := range op.Parameters {
-			skipParams = append(skipParams, pParam.Name)
+			if pParam.In == "path" {
+				skipParams = append(skipParams, pParam.Name)
+			}
 		}
-		paramName := reqType.Name()
-		s.defSkipFields[paramName] = skipParams
+		s.defSkipFields[reqType] = skipParams
 		s.bodyParamsProcessor(op, routeInfo)
 
 	}
 	s.processAuthParams(op, routeInfo.Parameters)
-	if routeInfo.Handler.OutputType != nil {
-		s.responseProcessor(op, *routeInfo.Handler.OutputType)
-	}
+	s.responseProcessor(op, routeInfo)
+	s.validationResponseProcessor(op, routeInfo.Handler)
+	s.declaredResponsesProcessor(op, routeInfo.Parameters.Responses)
 	return sPath, op
 }
 
-func (s *SwaggerGenerator) processDefinitions(definitionTypes map[string]reflect.Type) openapi.Definitions {
-	referencedDefinitions := make(map[string]reflect.Type)
+// processDefinitions generates definitions of referenced struct types. Types referenced by processed
+// definitions are added to queue and processed too
+func (s *SwaggerGenerator) processDefinitions() openapi.Definitions {
 	definitions := make(openapi.Definitions)
-	for definitionName, definitionType := range definitionTypes {
-		_, ok := s.processedDefinitions[definitionName]
-		if ok {
-			continue
-		}
-		defSkipFields, ok := s.defSkipFields[definitionName]
-		if !ok {
-			defSkipFields = make([]string, 0)
-		}
-		props := make(map[string]openapi.Schema)
-		for i := 0; i < definitionType.NumField(); i++ {
-			field := definitionType.Field(i)
-			fInfo := GetFieldInfo(field)
-			fieldName := fInfo.Name
-			var skip bool
-			for _, fName := range defSkipFields {
-				if fName == fieldName {
-					skip = true
-					break
-				}
-			}
-			if skip {
-				continue
-			}
+	for n := 0; n < len(s.definitionQueue); n++ {
+		definitionType := s.definitionQueue[n]
+		definitions[s.definitionName(definitionType)] = s.definitionSchema(definitionType)
+	}
+	return definitions
+}
 
-			schema, addition := getSchemaType(field.Type)
-			if addition != nil {
-				for defName, defType := range addition {
-					referencedDefinitions[defName] = defType
-				}
+// definitionSchema generates object schema of struct type. Fields of embedded structs are flattened
+// or, if enabled, embedded structs are referenced in allOf composition
+func (s *SwaggerGenerator) definitionSchema(definitionType reflect.Type) openapi.Schema {
+	if definitionType.Kind() != reflect.Struct || s.types.hasProvider(definitionType) {
+		// Recursive named slices, maps and self-described types
+		if schema := s.typeSchema(definitionType); schema != nil {
+			if schema.Description == "" {
+				schema.Description = s.docs.Type(definitionType)
 			}
-			if schema == nil {
-				continue
+			return *schema
+		}
+		return openapi.Schema{}
+	}
+	defSkipFields := s.defSkipFields[definitionType]
+	fields := JSONFields(definitionType)
+	var embeds []openapi.Schema
+	if s.embedsAllOf {
+		fields, embeds = s.embeddedSchemas(definitionType, fields)
+	}
+	props := make(map[string]openapi.Schema)
+	required := make([]string, 0)
+	for _, field := range fields {
+		fInfo := GetFieldInfo(field)
+		fieldName := fInfo.JSONName
+		var skip bool
+		for _, fName := range defSkipFields {
+			if fName == fInfo.Name {
+				skip = true
+				break
 			}
-			props[fieldName] = *schema
+		}
+		if skip {
+			continue
 		}
 
-		var definition openapi.Schema
-		definition.Type = []string{"object"}
-		definition.Properties = props
-		defName := getDefinitionName(definitionType)
-		definitions[defName] = definition
-		s.processedDefinitions[definitionName] = struct{}{}
-	}
-	if len(referencedDefinitions) > 0 {
-		refDefs := s.processReferencedDefinitions(referencedDefinitions)
-		for defName, defRes := range refDefs {
-			definitions[defName] = defRes
-			s.processedDefinitions[defName] = struct{}{}
+		schema := s.getSchemaType(field.Type)
+		if schema == nil {
+			continue
 		}
+		if stringEncoded(field, fInfo) {
+			stringNumberSchema(schema)
+		}
+		validated := applyFieldRules(schema, field)
+		if s.requiredPolicy.isRequired(field, fInfo, validated) {
+			required = append(required, fieldName)
+		}
+		docs := parseDocTags(field)
+		if docs.description == "" {
+			docs.description = s.docs.Field(definitionType, field)
+		}
+		docs.applyToSchema(schema)
+		props[fieldName] = *schema
 	}
-	return definitions
+	required = s.applyUnionVariants(definitionType, props, required)
+	bases, discriminatorValue := s.unionBases(definitionType)
+	embeds = append(bases, embeds...)
+
+	var definition openapi.Schema
+	definition.Type = []string{"object"}
+	definition.Properties = props
+	if len(required) > 0 {
+		definition.Required = required
+	}
+	if len(embeds) == 0 {
+		definition.Description = s.docs.Type(definitionType)
+		return definition
+	}
+	var composed openapi.Schema
+	composed.AllOf = append(embeds, definition)
+	composed.Description = s.docs.Type(definitionType)
+	if discriminatorValue != "" {
+		composed.AddExtension("x-discriminator-value", discriminatorValue)
+	}
+	return composed
 }
 
-func (s *SwaggerGenerator) processReferencedDefinitions(definitionTypes map[string]reflect.Type) openapi.Definitions {
-	definitions := make(openapi.Definitions)
-	structDefinitions := make(map[string]reflect.Type)
-	for definitionName, definitionType := range definitionTypes {
-		_, ok := s.processedDefinitions[definitionName]
-		if ok {
-			continue
-		}
-		defKind := definitionType.Kind()
-		defName := getDefinitionName(definitionType)
-		switch defKind {
-		case reflect.Struct:
-			structDefinitions[defName] = definitionType
-		default:
-			var definition openapi.Schema
-			definition.Type = processNonStructDefinitionType(defKind)
-			definitions[defName] = definition
+// getSchemaType returns schema of type. Registered and self-described types schemas are used as is, then
+// schemas of standard library and marshaler types. Struct types are referenced and added to definitions.
+// Named slices, maps and self-described types referring themselves are added to definitions too
+func (s *SwaggerGenerator) getSchemaType(paramType reflect.Type) *openapi.Schema {
+	for paramType.Kind() == reflect.Ptr {
+		paramType = paramType.Elem()
+	}
+	if schema, ok := s.types.Schema(paramType); ok {
+		return schema
+	}
+	if elem, nullable, ok := OptionalElem(paramType); ok {
+		schema := s.getSchemaType(elem)
+		if schema != nil && nullable {
+			return nullableSchema(schema)
 		}
+		return schema
+	}
+	if _, ok := s.types.Union(paramType); ok {
+		return s.definitionRef(paramType)
 	}
-	if len(structDefinitions) > 0 {
-		structDefs := s.processDefinitions(structDefinitions)
-		for defName, definition := range structDefs {
-			definitions[defName] = definition
+	if !s.mayReferItself(paramType) {
+		schema := s.typeSchema(paramType)
+		if values := EnumValues(paramType, s.docs); len(values) > 0 && schema != nil {
+			schema.Enum = values
+			if paramType.Kind() != reflect.String && schema.Type.Contains("string") {
+				// Numbers encoded as strings
+				schema.Enum = stringValues(values)
+			}
 		}
+		return schema
 	}
-	return definitions
+	if _, ok := s.definitionTypes[paramType]; ok || s.resolving[paramType] {
+		return s.definitionRef(paramType)
+	}
+	s.resolving[paramType] = true
+	schema := s.typeSchema(paramType)
+	delete(s.resolving, paramType)
+	if _, ok := s.definitionTypes[paramType]; ok {
+		// Type was referenced while its schema was generated
+		return s.definitionRef(paramType)
+	}
+	return schema
 }
 
-func processNonStructDefinitionType(defKind reflect.Kind) []string {
-	switch defKind {
-	case reflect.String:
-		return []string{"string"}
-	case reflect.Float32, reflect.Float64:
-		return []string{"number"}
-	case reflect.Bool:
-		return []string{"boolean"}
-	default:
-		if reflect.Int <= defKind && defKind <= reflect.Uint64 {
-			return []string{"integer"}
-		}
+// mayReferItself reports if schema of type is generated inline and can contain the type itself.
+// Struct types are always referenced, so only named slices, maps and self-described types are checked
+func (s *SwaggerGenerator) mayReferItself(t reflect.Type) bool {
+	if t.Name() == "" {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return builtinSchema(t) == nil
 	}
-	return []string{}
+	return s.types.hasProvider(t)
 }
 
-func getSchemaType(paramType reflect.Type) (*openapi.Schema, map[string]reflect.Type) {
+// typeSchema returns schema of not registered type
+func (s *SwaggerGenerator) typeSchema(paramType reflect.Type) *openapi.Schema {
+	if schema, ok := s.types.providedSchema(paramType, s.resolveSchema); ok {
+		return schema
+	}
+	if schema := builtinSchema(paramType); schema != nil {
+		if s.int64AsString && paramType == durationType {
+			stringNumberSchema(schema)
+		}
+		return schema
+	}
 	switch paramType.Kind() {
 	case reflect.Bool:
-		return openapi.BoolProperty(), nil
+		return openapi.BoolProperty()
 	case reflect.Int8:
-		return openapi.Int8Property(), nil
+		return openapi.Int8Property()
 	case reflect.Int16:
-		return openapi.Int16Property(), nil
+		return openapi.Int16Property()
 	case reflect.Int32:
-		return openapi.Int32Property(), nil
+		return openapi.Int32Property()
 	case reflect.Int, reflect.Int64:
-		return openapi.Int64Property(), nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return openapi.Int64Property(), nil
+		schema := openapi.Int64Property()
+		if s.int64AsString {
+			stringNumberSchema(schema)
+		}
+		return schema
+	case reflect.Uint, reflect.Uint64:
+		schema := unsignedSchema(paramType.Kind())
+		if s.int64AsString {
+			stringNumberSchema(schema)
+		}
+		return schema
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return unsignedSchema(paramType.Kind())
 	case reflect.Float32:
-		return openapi.Float32Property(), nil
+		return openapi.Float32Property()
 	case reflect.Float64:
-		return openapi.Float64Property(), nil
+		return openapi.Float64Property()
 	case reflect.String:
-		return openapi.StringProperty(), nil
-	case reflect.Slice:
-		fieldType, additionalDefinition := getSchemaType(paramType.Elem())
-		return openapi.ArrayProperty(fieldType), additionalDefinition
-	case reflect.Array:
-		fieldType, additionalDefinition := getSchemaType(paramType.Elem())
-		return openapi.ArrayProperty(fieldType), additionalDefinition
-	case reflect.Map:
-		fieldType, additionalDefinition := getSchemaType(paramType.Elem())
-		return openapi.MapProperty(fieldType), additionalDefinition
-	case reflect.Struct:
-		if paramType == timeType {
-			return openapi.DateTimeProperty(), nil
+		return openapi.StringProperty()
+	case reflect.Slice, reflect.Array:
+		fieldType := s.getSchemaType(paramType.Elem())
+		if fieldType == nil {
+			return nil
 		}
-		addition := map[string]reflect.Type{paramType.Name(): paramType}
-		defName := getDefinitionName(paramType)
-		return openapi.RefProperty(
-			fmt.Sprintf("#/definitions/%s", defName),
-		), addition
-	case reflect.Ptr:
-		refVal := reflect.New(paramType).Elem()
-		refElem := refVal.Type().Elem()
-		if refElem == timeType {
-			return openapi.DateTimeProperty(), nil
+		return openapi.ArrayProperty(fieldType)
+	case reflect.Map:
+		fieldType := s.getSchemaType(paramType.Elem())
+		if fieldType == nil {
+			return nil
 		}
-		defName := getDefinitionName(refElem)
-		resType := openapi.RefProperty(
-			fmt.Sprintf("#/definitions/%s", defName),
-		)
-		addition := map[string]reflect.Type{refElem.Name(): refElem}
-		return resType, addition
+		return openapi.MapProperty(fieldType)
+	case reflect.Struct:
+		return s.definitionRef(paramType)
 	case reflect.Interface:
+		if union, ok := s.types.Union(paramType); ok {
+			return s.unionSchema(union)
+		}
 		return &openapi.Schema{
 			SchemaProps: openapi.SchemaProps{
 				AnyOf: []openapi.Schema{
@@ -381,7 +595,7 @@ func getSchemaType(paramType reflect.Type) (*openapi.Schema, map[string]reflect.
 					*openapi.BoolProperty(),
 				}},
 			SwaggerSchemaProps: openapi.SwaggerSchemaProps{Example: "any_value"},
-		}, nil
+		}
 	}
-	return nil, nil
+	return nil
 }
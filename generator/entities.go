@@ -9,9 +9,13 @@ const (
 )
 
 type HandlerInfo struct {
+	// FuncName is runtime name of handler function, used to find its doc comment
+	FuncName    string
 	RequestType *reflect.Type
 	OutputType  *reflect.Type
 	FileUpload  []FileUploadParameters
+	// ValidationErrorType is response body for requests rejected by validate tags. Documented as 422 response
+	ValidationErrorType *reflect.Type
 }
 
 type FileUploadParameters struct {
@@ -44,10 +48,24 @@ type AuthType struct {
 	APIKey       *APIKeyParams
 }
 
+// ResponseParameters describes additional operation response, like 404 or 409 error
+type ResponseParameters struct {
+	// Status of response. Zero status is documented as default response
+	Status      int
+	Description string
+	// Body is value of response body type, like wrapper.ProblemDetails{}. Nil for responses without body
+	Body interface{}
+}
+
 type HandlerParameters struct {
-	Summary    string
-	Auth       []AuthType
-	FileUpload []FileUploadParameters
+	Summary string
+	// Description of operation, handler doc comment is used if empty and doc comments are loaded
+	Description string
+	Auth        []AuthType
+	FileUpload  []FileUploadParameters
+	Responses   []ResponseParameters
+	// SuccessStatus is status of successful response. By default 200, or 204 for handlers returning only error
+	SuccessStatus int
 }
 
 type RouteInfo struct {
@@ -58,7 +76,67 @@ type RouteInfo struct {
 }
 
 type fieldInfo struct {
-	Name     string
-	JSONName string
-	In       string
+	Name             string
+	JSONName         string
+	In               string
+	CollectionFormat string
+	// OmitEmpty is set by omitempty option of json tag
+	OmitEmpty bool
+	// AsString is set by string option of json tag
+	AsString bool
+}
+
+// ErrorCode describes stable machine-readable error code returned by API. Exported to spec as x-error-codes
+type ErrorCode struct {
+	Code    string `json:"code"`
+	Status  int    `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// SpecOptions describes top-level spec metadata
+type SpecOptions struct {
+	// Title of API, "API" by default
+	Title       string
+	Description string
+	// Version of API, "1.0" by default
+	Version        string
+	TermsOfService string
+	Contact        *ContactInfo
+	License        *LicenseInfo
+	// Host, BasePath and Schemes define API location. For OpenAPI 3 they are converted to servers
+	Host     string
+	BasePath string
+	Schemes  []string
+	// Servers are OpenAPI 3 servers, override servers built from Host, BasePath and Schemes
+	Servers      []ServerInfo
+	ExternalDocs *ExternalDocs
+	// Tags describe operation tags. Tags order defines groups order in UI
+	Tags []TagInfo
+}
+
+type ContactInfo struct {
+	Name  string
+	URL   string
+	Email string
+}
+
+type LicenseInfo struct {
+	Name string
+	URL  string
+}
+
+type ServerInfo struct {
+	URL         string
+	Description string
+}
+
+type ExternalDocs struct {
+	Description string
+	URL         string
+}
+
+type TagInfo struct {
+	Name         string
+	Description  string
+	ExternalDocs *ExternalDocs
 }
@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+)
+
+// EnumProvider is implemented by named types with fixed set of values, like type Status string.
+// Values are documented as schema enum
+type EnumProvider interface {
+	Enum() []interface{}
+}
+
+var enumProviderIface = reflect.TypeOf((*EnumProvider)(nil)).Elem()
+
+// EnumValues returns allowed values of named string, number or boolean type. Values are returned by Enum method
+// of type or, if doc comments are loaded, found in const blocks declaring several constants of the type
+func EnumValues(t reflect.Type, docs *DocComments) []interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if elem, _, ok := OptionalElem(t); ok {
+		return EnumValues(elem, docs)
+	}
+	if t.Name() == "" || !isEnumKind(t.Kind()) {
+		return nil
+	}
+	if reflect.PtrTo(t).Implements(enumProviderIface) {
+		return reflect.New(t).Interface().(EnumProvider).Enum()
+	}
+	if isMarshaler(t) {
+		// Constants values differ from encoded values
+		return nil
+	}
+	return docs.Enum(t)
+}
+
+func isEnumKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// Enum returns values of constants declared with named type, in order of declaration
+func (d *DocComments) Enum(t reflect.Type) []interface{} {
+	if d == nil {
+		return nil
+	}
+	return d.enums[docTypeKey(t)]
+}
+
+// sourceImporter imports nothing, constants of package are evaluated without its dependencies
+type sourceImporter struct{}
+
+func (sourceImporter) Import(path string) (*types.Package, error) {
+	return nil, errors.New("imports are not resolved")
+}
+
+var _ types.Importer = sourceImporter{}
+
+// addEnums collects values of constants of named types declared in package files. Type is enum when
+// const block declares several its constants, single constants like default values are not enums.
+// Files are type checked from sources, constants depending on imported packages are skipped
+func (d *DocComments) addEnums(fset *token.FileSet, pkgPath string, files []*ast.File) {
+	conf := types.Config{
+		Importer: sourceImporter{},
+		// Errors of unresolved imports are expected
+		Error: func(error) {},
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	if pkg, _ := conf.Check(pkgPath, fset, files, info); pkg == nil {
+		return
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST || !genDecl.Lparen.IsValid() {
+				continue
+			}
+			d.addConstBlock(genDecl, info)
+		}
+	}
+}
+
+// addConstBlock adds values of constants of named types declared at least twice in const block
+func (d *DocComments) addConstBlock(decl *ast.GenDecl, info *types.Info) {
+	blockConsts := make(map[string][]*types.Const)
+	keys := make([]string, 0)
+	for _, spec := range decl.Specs {
+		for _, name := range spec.(*ast.ValueSpec).Names {
+			c, ok := info.Defs[name].(*types.Const)
+			if !ok {
+				continue
+			}
+			named, ok := c.Type().(*types.Named)
+			if !ok || named.Obj().Pkg() == nil {
+				continue
+			}
+			key := named.Obj().Pkg().Path() + "." + named.Obj().Name()
+			if _, ok := blockConsts[key]; !ok {
+				keys = append(keys, key)
+			}
+			blockConsts[key] = append(blockConsts[key], c)
+		}
+	}
+	for _, key := range keys {
+		consts := blockConsts[key]
+		if len(consts) < 2 {
+			continue
+		}
+		for _, c := range consts {
+			// Package can be added several times
+			constKey := c.Pkg().Path() + "." + c.Name()
+			if _, ok := d.enumConsts[constKey]; ok {
+				continue
+			}
+			d.enumConsts[constKey] = struct{}{}
+			val := constantValue(c.Val())
+			if val == nil || containsValue(d.enums[key], val) {
+				continue
+			}
+			d.enums[key] = append(d.enums[key], val)
+		}
+	}
+}
+
+func constantValue(val constant.Value) interface{} {
+	switch val.Kind() {
+	case constant.String:
+		return constant.StringVal(val)
+	case constant.Bool:
+		return constant.BoolVal(val)
+	case constant.Int:
+		if iVal, ok := constant.Int64Val(val); ok {
+			return iVal
+		}
+		if uVal, ok := constant.Uint64Val(val); ok {
+			return uVal
+		}
+	case constant.Float:
+		fVal, _ := constant.Float64Val(val)
+		return fVal
+	}
+	return nil
+}
+
+// stringValues returns values formatted as strings, like encoded by ",string" json tag option
+func stringValues(values []interface{}) []interface{} {
+	if len(values) == 0 {
+		return values
+	}
+	res := make([]interface{}, 0, len(values))
+	for _, val := range values {
+		res = append(res, fmt.Sprint(val))
+	}
+	return res
+}
+
+func containsValue(values []interface{}, val interface{}) bool {
+	for _, v := range values {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -5,9 +5,7 @@ import (
 	openapi "github.com/go-openapi/spec"
 	"github.com/google/uuid"
 	"reflect"
-	"strings"
 	"time"
-	"unicode"
 )
 
 const definitionPrefix = "#/definitions/"
@@ -16,14 +14,38 @@ func float64Ptr(f float64) *float64 {
 	return &f
 }
 
-func unsignedType(schema *openapi.Schema) *openapi.Schema {
-	schema.SchemaProps.Minimum = float64Ptr(0)
+// unsignedSchema returns schema of unsigned integer kind: format of wider signed type and minimum 0
+func unsignedSchema(kind reflect.Kind) *openapi.Schema {
+	schema := &openapi.Schema{}
+	schema.Typed("integer", unsignedFormat(kind))
+	schema.Minimum = float64Ptr(0)
 	return schema
 }
 
+// unsignedFormat returns format of signed type wider than unsigned integer kind. uint64 values exceed
+// all integer formats, so uint and uint64 have no format
+func unsignedFormat(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Uint8:
+		return "int16"
+	case reflect.Uint16:
+		return "int32"
+	case reflect.Uint32:
+		return "int64"
+	}
+	return ""
+}
+
 type SchemaGenerator struct {
 	processTypes []reflect.Type
 	cTypes       map[reflect.Type]*openapi.Schema
+	// queued holds types added to processTypes
+	queued map[reflect.Type]bool
+	// resolving holds named slices and maps which schemas are being generated
+	resolving map[reflect.Type]bool
+	types     *TypeRegistry
+	namer     DefinitionNamer
+	names     *definitionNames
 }
 
 var simpleTypesMapping = map[reflect.Kind]*openapi.Schema{
@@ -33,11 +55,11 @@ var simpleTypesMapping = map[reflect.Kind]*openapi.Schema{
 	reflect.Int16:   openapi.Int16Property(),
 	reflect.Int32:   openapi.Int32Property(),
 	reflect.Int64:   openapi.Int64Property(),
-	reflect.Uint:    unsignedType(openapi.Int64Property()),
-	reflect.Uint8:   unsignedType(openapi.Int8Property()),
-	reflect.Uint16:  unsignedType(openapi.Int16Property()),
-	reflect.Uint32:  unsignedType(openapi.Int32Property()),
-	reflect.Uint64:  unsignedType(openapi.Int64Property()),
+	reflect.Uint:    unsignedSchema(reflect.Uint),
+	reflect.Uint8:   unsignedSchema(reflect.Uint8),
+	reflect.Uint16:  unsignedSchema(reflect.Uint16),
+	reflect.Uint32:  unsignedSchema(reflect.Uint32),
+	reflect.Uint64:  unsignedSchema(reflect.Uint64),
 	reflect.Float32: openapi.Float32Property(),
 	reflect.Float64: openapi.Float64Property(),
 	reflect.String:  openapi.StringProperty(),
@@ -52,33 +74,156 @@ func NewSchemaGenerator() *SchemaGenerator {
 			timeType: openapi.DateTimeProperty(),
 			uuidType: UUIDProperty(),
 		},
+		queued:    make(map[reflect.Type]bool),
+		resolving: make(map[reflect.Type]bool),
+		names:     newDefinitionNames(nil),
 	}
 }
 
+// SetTypeRegistry sets registry of types schemas
+func (gen *SchemaGenerator) SetTypeRegistry(registry *TypeRegistry) {
+	gen.types = registry
+}
+
+// SetDefinitionNamer sets naming strategy of definitions, ShortDefinitionName by default
+func (gen *SchemaGenerator) SetDefinitionNamer(namer DefinitionNamer) {
+	gen.namer = namer
+}
+
+// GetSchema returns definitions of type and types it refers. Colliding definitions names are disambiguated
+// like by SwaggerGenerator
 func (gen *SchemaGenerator) GetSchema(paramType reflect.Type) (openapi.Definitions, error) {
+	// Definitions are generated twice: to collect definitions types and with names assigned to all of them
+	gen.reset()
+	_, definitionTypes, err := gen.definitions(paramType)
+	if err != nil {
+		return nil, err
+	}
+	gen.reset()
+	gen.names.assign(definitionTypes)
+	defs, _, err := gen.definitions(paramType)
+	return defs, err
+}
+
+// reset clears types and their names collected by definitions generation
+func (gen *SchemaGenerator) reset() {
+	gen.processTypes = make([]reflect.Type, 0)
+	gen.queued = make(map[reflect.Type]bool)
+	gen.resolving = make(map[reflect.Type]bool)
+	gen.names = newDefinitionNames(gen.namer)
+}
+
+// definitions generates definitions of type and types it refers, returns types of generated definitions
+func (gen *SchemaGenerator) definitions(paramType reflect.Type) (openapi.Definitions, []reflect.Type, error) {
 	defs := openapi.Definitions{}
-	gen.processTypes = append(gen.processTypes, paramType)
+	definitionTypes := make([]reflect.Type, 0)
+	gen.queue(paramType)
 	for i := 0; i < len(gen.processTypes); i++ {
 		param := gen.processTypes[i]
-		if param.Kind() != reflect.Struct {
-			// can generate schema only for structs
+		if _, ok := gen.types.Schema(param); ok {
+			// Registered types schemas are used inline
+			continue
+		}
+		if provided := gen.types.hasProvider(param); param.Kind() != reflect.Struct || provided {
+			if param.Name() == "" || (paramType == param && !provided) {
+				// can generate schema only for structs, recursive named types and self-described types
+				continue
+			}
+			schema, err := gen.typeSchema(param)
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot process parameter %s: %w", gen.names.name(param), err)
+			}
+			if schema != nil {
+				defs[definitionPrefix+gen.names.name(param)] = *schema
+				definitionTypes = append(definitionTypes, param)
+			}
 			continue
 		}
-		defStructName := getDefinitionName(param)
+		defStructName := gen.names.name(param)
 		structSchema, err := gen.processStruct(param)
 		if err != nil {
-			return nil, fmt.Errorf("cannot process parameter %s: %w", defStructName, err)
+			return nil, nil, fmt.Errorf("cannot process parameter %s: %w", defStructName, err)
 		}
 		defName := definitionPrefix + defStructName
 		defs[defName] = *structSchema
+		definitionTypes = append(definitionTypes, param)
+	}
+	return defs, definitionTypes, nil
+}
+
+// queue adds type to processTypes once
+func (gen *SchemaGenerator) queue(paramType reflect.Type) {
+	if gen.queued[paramType] {
+		return
 	}
-	return defs, nil
+	gen.queued[paramType] = true
+	gen.processTypes = append(gen.processTypes, paramType)
 }
 
+// processParam returns schema of type, registered types schemas are used as is. Named slices and maps
+// referring themselves are referenced and added to definitions like structs
 func (gen *SchemaGenerator) processParam(paramType reflect.Type) (*openapi.Schema, error) {
+	if schema, ok := gen.types.Schema(paramType); ok {
+		return schema, nil
+	}
+	if elem, nullable, ok := OptionalElem(paramType); ok {
+		schema, err := gen.processParam(elem)
+		if schema != nil && nullable {
+			return nullableSchema(schema), err
+		}
+		return schema, err
+	}
+	if !gen.mayReferItself(paramType) {
+		return gen.typeSchema(paramType)
+	}
+	if gen.queued[paramType] || gen.resolving[paramType] {
+		gen.queue(paramType)
+		return openapi.RefProperty(definitionPrefix + gen.names.name(paramType)), nil
+	}
+	gen.resolving[paramType] = true
+	schema, err := gen.typeSchema(paramType)
+	delete(gen.resolving, paramType)
+	if gen.queued[paramType] {
+		// Type was referenced while its schema was generated
+		return openapi.RefProperty(definitionPrefix + gen.names.name(paramType)), err
+	}
+	return schema, err
+}
+
+// mayReferItself reports if type is named slice, map or self-described type, which schema is generated inline
+// and can contain the type itself
+func (gen *SchemaGenerator) mayReferItself(t reflect.Type) bool {
+	if t.Name() == "" || gen.tryCustomType(t) != nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return builtinSchema(t) == nil
+	}
+	return gen.types.hasProvider(t)
+}
+
+// typeSchema returns schema of type, struct types are referenced
+func (gen *SchemaGenerator) typeSchema(paramType reflect.Type) (*openapi.Schema, error) {
+	cType := gen.tryCustomType(paramType)
+	if cType != nil {
+		return cType, nil
+	}
+	if providedSchema, ok := gen.types.providedSchema(paramType, gen.resolveSchema); ok {
+		return providedSchema, nil
+	}
+	if schema := builtinSchema(paramType); schema != nil {
+		return schema, nil
+	}
 	paramKind := paramType.Kind()
 	simpleType, ok := simpleTypesMapping[paramKind]
 	if ok {
+		if values := EnumValues(paramType, nil); len(values) > 0 {
+			// Simple types schemas are shared, enum is set to copy
+			enumType := *simpleType
+			enumType.Enum = values
+			return &enumType, nil
+		}
 		return simpleType, nil
 	}
 	switch paramType.Kind() {
@@ -115,8 +260,8 @@ func (gen *SchemaGenerator) processParam(paramType reflect.Type) (*openapi.Schem
 		if cType != nil {
 			return cType, nil
 		}
-		defName := getDefinitionName(paramType)
-		gen.processTypes = append(gen.processTypes, paramType)
+		defName := gen.names.name(paramType)
+		gen.queue(paramType)
 		return openapi.RefProperty(
 			definitionPrefix + defName,
 		), nil
@@ -140,23 +285,29 @@ func (gen *SchemaGenerator) processParam(paramType reflect.Type) (*openapi.Schem
 	return nil, nil
 }
 
+// resolveSchema is SchemaResolver of schema generator
+func (gen *SchemaGenerator) resolveSchema(value interface{}) openapi.Schema {
+	if value == nil {
+		return openapi.Schema{}
+	}
+	schema, err := gen.processParam(reflect.TypeOf(value))
+	if err != nil || schema == nil {
+		return openapi.Schema{}
+	}
+	return *schema
+}
+
 func (gen *SchemaGenerator) processStruct(paramType reflect.Type) (*openapi.Schema, error) {
 	res := &openapi.Schema{}
 	res.Type = []string{"object"}
 	res.Properties = openapi.SchemaProperties{}
 
-	for i := 0; i < paramType.NumField(); i++ {
-		field := paramType.Field(i)
-		if unicode.IsLower([]rune(field.Name)[0]) {
+	for _, field := range JSONFields(paramType) {
+		fInfo := GetFieldInfo(field)
+		if fInfo == nil {
 			continue
 		}
-		fieldName := field.Tag.Get("json")
-		if fieldName == "-" {
-			continue
-		}
-		if fieldName == "" {
-			fieldName = field.Name
-		}
+		fieldName := fInfo.JSONName
 
 		schema, err := gen.processParam(field.Type)
 		if err != nil {
@@ -166,7 +317,16 @@ func (gen *SchemaGenerator) processStruct(paramType reflect.Type) (*openapi.Sche
 			// Pass unsupported types
 			continue
 		}
-		res.Properties[fieldName] = *schema
+		// Schema can be shared between fields, constraints are applied to copy
+		fieldSchema := *schema
+		if stringEncoded(field, fInfo) {
+			stringNumberSchema(&fieldSchema)
+		}
+		if applyFieldRules(&fieldSchema, field) && !isOptional(field.Type) {
+			res.Required = append(res.Required, fieldName)
+		}
+		parseDocTags(field).applyToSchema(&fieldSchema)
+		res.Properties[fieldName] = fieldSchema
 	}
 	return res, nil
 }
@@ -199,13 +359,3 @@ func UUIDProperty() *openapi.Schema {
 	fType.SchemaProps.Format = "uuid"
 	return fType
 }
-
-func getDefinitionName(defType reflect.Type) string {
-	pParts := strings.Split(defType.PkgPath(), "/")
-	lastPart := pParts[len(pParts)-1]
-	if len(lastPart) > 0 {
-		lastPart += "."
-	}
-	defName := lastPart + defType.Name()
-	return defName
-}
@@ -1,9 +1,19 @@
 package generator
 
 import (
+	"context"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator/openapi3"
+	openapi "github.com/go-openapi/spec"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"math/big"
+	"mime/multipart"
+	"net"
+	"net/netip"
+	"net/url"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -377,7 +387,7 @@ func TestGetSchemaWithInterface(t *testing.T) {
 		t.Errorf("not found prop for id field")
 	} else {
 		assert.Equal(t, "integer", numProp.Type[0])
-		assert.Equal(t, "int64", numProp.Format)
+		assert.Equal(t, "", numProp.Format)
 		assert.NotNil(t, numProp.Minimum)
 		assert.Equal(t, float64(0), *numProp.Minimum)
 	}
@@ -388,3 +398,1493 @@ func TestGetSchemaWithInterface(t *testing.T) {
 		assert.Greater(t, 1, len(interfaceProp.Type))
 	}
 }
+
+type TypedQueryReq struct {
+	ID     uuid.UUID    `json:"id"`
+	Offset int          `json:"offset"`
+	Ratio  float32      `json:"ratio"`
+	Active *bool        `json:"active"`
+	Since  time.Time    `json:"since"`
+	Sub    SimpleStruct `json:"sub"`
+	Level  uint8        `json:"level"`
+	Total  uint64       `json:"total"`
+	Parts  []uint16     `json:"parts"`
+}
+
+func TestEmitTypedParams(t *testing.T) {
+	reqType := reflect.TypeOf(TypedQueryReq{})
+	routes := map[string]RouteInfo{
+		"GET~/items/:id": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	op := sw.Paths.Paths["/items/{id}"].Get
+	if !assert.NotNil(t, op) {
+		return
+	}
+	want := map[string][3]string{
+		"id":        {"path", "string", "uuid"},
+		"offset":    {"query", "integer", "int64"},
+		"ratio":     {"query", "number", "float"},
+		"active":    {"query", "boolean", ""},
+		"since":     {"query", "string", "date-time"},
+		"sub[Name]": {"query", "string", ""},
+		"level":     {"query", "integer", "int16"},
+		"total":     {"query", "integer", ""},
+		"parts":     {"query", "array", ""},
+	}
+	assert.Equal(t, len(want), len(op.Parameters))
+	for _, param := range op.Parameters {
+		wantParam, ok := want[param.Name]
+		if !ok {
+			t.Errorf("unexpected parameter %s", param.Name)
+			continue
+		}
+		assert.Equal(t, wantParam[0], param.In)
+		assert.Equal(t, wantParam[1], param.Type)
+		assert.Equal(t, wantParam[2], param.Format)
+		switch param.Name {
+		case "level", "total":
+			if assert.NotNil(t, param.Minimum, param.Name) {
+				assert.Equal(t, float64(0), *param.Minimum)
+			}
+		case "parts":
+			assert.Equal(t, "int32", param.Items.Format)
+			if assert.NotNil(t, param.Items.Minimum) {
+				assert.Equal(t, float64(0), *param.Items.Minimum)
+			}
+		default:
+			assert.Nil(t, param.Minimum, param.Name)
+		}
+	}
+}
+
+type UnsignedCounters struct {
+	Small  uint8  `json:"small"`
+	Medium uint16 `json:"medium"`
+	Large  uint32 `json:"large"`
+	Huge   uint64 `json:"huge"`
+	Plain  uint   `json:"plain"`
+}
+
+func TestUnsignedSchemas(t *testing.T) {
+	respType := reflect.TypeOf(UnsignedCounters{})
+	routes := map[string]RouteInfo{
+		"GET~/counters": {
+			Method:  "GET",
+			Handler: HandlerInfo{OutputType: &respType},
+		},
+	}
+	sw, err := NewSwaggerGenerator().EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	defs, err := NewSchemaGenerator().GetSchema(respType)
+	assert.NoError(t, err)
+	want := map[string]string{"small": "int16", "medium": "int32", "large": "int64", "huge": "", "plain": ""}
+	for _, def := range []openapi.Schema{
+		sw.Definitions["generator.UnsignedCounters"],
+		defs[definitionPrefix+"generator.UnsignedCounters"],
+	} {
+		assert.Equal(t, len(want), len(def.Properties))
+		for name, format := range want {
+			prop := def.Properties[name]
+			assert.Equal(t, openapi.StringOrArray{"integer"}, prop.Type, name)
+			assert.Equal(t, format, prop.Format, name)
+			if assert.NotNil(t, prop.Minimum, name) {
+				assert.Equal(t, float64(0), *prop.Minimum)
+			}
+		}
+	}
+}
+
+type HeaderCookieReq struct {
+	Tenant  string `param:"X-Tenant-ID,header"`
+	Session string `param:"session,cookie"`
+	Name    string `json:"name"`
+}
+
+func TestEmitHeaderCookieParams(t *testing.T) {
+	reqType := reflect.TypeOf(HeaderCookieReq{})
+	routes := map[string]RouteInfo{
+		"POST~/items": {
+			Method:  "POST",
+			Handler: HandlerInfo{RequestType: &reqType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	op := sw.Paths.Paths["/items"].Post
+	if !assert.NotNil(t, op) {
+		return
+	}
+	locations := make(map[string]string)
+	for _, param := range op.Parameters {
+		locations[param.Name] = param.In
+	}
+	assert.Equal(t, "header", locations["X-Tenant-ID"])
+	assert.Equal(t, "cookie", locations["session"])
+	assert.Equal(t, "body", locations["HeaderCookieReq"])
+	def, ok := sw.Definitions["generator.HeaderCookieReq"]
+	if assert.True(t, ok) {
+		assert.Equal(t, 1, len(def.Properties))
+		_, ok = def.Properties["name"]
+		assert.True(t, ok)
+	}
+}
+
+type ListFilter struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+type ArrayQueryReq struct {
+	Status []string          `json:"status" param:"status,query,multi"`
+	IDs    []int64           `json:"ids" param:"ids,query,csv"`
+	Tags   []string          `param:"X-Tags,header,multi"`
+	Filter ListFilter        `json:"filter"`
+	Labels map[string]string `json:"labels"`
+}
+
+func TestEmitArrayAndObjectParams(t *testing.T) {
+	reqType := reflect.TypeOf(ArrayQueryReq{})
+	routes := map[string]RouteInfo{
+		"GET~/items": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	op := sw.Paths.Paths["/items"].Get
+	if !assert.NotNil(t, op) {
+		return
+	}
+	params := make(map[string]openapi.Parameter)
+	for _, param := range op.Parameters {
+		params[param.Name] = param
+	}
+	assert.Equal(t, 6, len(params))
+
+	status := params["status"]
+	assert.Equal(t, "array", status.Type)
+	assert.Equal(t, "multi", status.CollectionFormat)
+	if assert.NotNil(t, status.Items) {
+		assert.Equal(t, "string", status.Items.Type)
+	}
+	ids := params["ids"]
+	assert.Equal(t, "array", ids.Type)
+	assert.Equal(t, "csv", ids.CollectionFormat)
+	if assert.NotNil(t, ids.Items) {
+		assert.Equal(t, "integer", ids.Items.Type)
+		assert.Equal(t, "int64", ids.Items.Format)
+	}
+	tags := params["X-Tags"]
+	assert.Equal(t, "header", tags.In)
+	assert.Equal(t, "csv", tags.CollectionFormat)
+
+	assert.Equal(t, "string", params["filter[name]"].Type)
+	assert.Equal(t, "integer", params["filter[age]"].Type)
+	assert.Equal(t, "string", params["labels[key]"].Type)
+}
+
+type ValidatedReq struct {
+	Login  string   `json:"login" validate:"required,min=3,max=20,alphanum"`
+	Email  string   `json:"email" validate:"email"`
+	Age    int      `json:"age" validate:"gte=18,lt=150"`
+	Role   string   `json:"role" validate:"oneof=admin user"`
+	Tags   []string `json:"tags" validate:"max=5"`
+	Limit  int      `param:"limit,query" validate:"required,max=100"`
+	Ignore string   `json:"ignore"`
+}
+
+type ValidationErrorBody struct {
+	Message string `json:"message"`
+}
+
+func TestEmitValidationRules(t *testing.T) {
+	reqType := reflect.TypeOf(ValidatedReq{})
+	errType := reflect.TypeOf(ValidationErrorBody{})
+	routes := map[string]RouteInfo{
+		"POST~/users": {
+			Method:  "POST",
+			Handler: HandlerInfo{RequestType: &reqType, ValidationErrorType: &errType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	op := sw.Paths.Paths["/users"].Post
+	if !assert.NotNil(t, op) {
+		return
+	}
+	for _, param := range op.Parameters {
+		if param.Name == "limit" {
+			assert.True(t, param.Required)
+			assert.Equal(t, float64(100), *param.Maximum)
+		}
+	}
+	if assert.NotNil(t, op.Responses) {
+		resp, ok := op.Responses.StatusCodeResponses[422]
+		assert.True(t, ok)
+		assert.Equal(t, "#/definitions/generator.ValidationErrorBody", resp.Schema.Ref.String())
+	}
+	_, ok := sw.Definitions["generator.ValidationErrorBody"]
+	assert.True(t, ok)
+
+	def, ok := sw.Definitions["generator.ValidatedReq"]
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, []string{"login"}, def.Required)
+	login := def.Properties["login"]
+	assert.Equal(t, int64(3), *login.MinLength)
+	assert.Equal(t, int64(20), *login.MaxLength)
+	assert.Equal(t, "^[a-zA-Z0-9]+$", login.Pattern)
+	assert.Equal(t, "email", def.Properties["email"].Format)
+	age := def.Properties["age"]
+	assert.Equal(t, float64(18), *age.Minimum)
+	assert.Equal(t, float64(150), *age.Maximum)
+	assert.True(t, age.ExclusiveMaximum)
+	assert.Equal(t, []interface{}{"admin", "user"}, def.Properties["role"].Enum)
+	assert.Equal(t, int64(5), *def.Properties["tags"].MaxItems)
+}
+
+type ErrorBody struct {
+	Code    string        `json:"code"`
+	Details []ErrorDetail `json:"details"`
+}
+
+type ErrorDetail struct {
+	Field string `json:"field"`
+}
+
+func TestEmitDeclaredResponses(t *testing.T) {
+	reqType := reflect.TypeOf(SimpleStruct{})
+	respType := reflect.TypeOf(WithUUID{})
+	routes := map[string]RouteInfo{
+		"GET~/users/:id": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &respType},
+			Parameters: HandlerParameters{
+				Responses: []ResponseParameters{
+					{Status: 404, Description: "User not found", Body: ErrorBody{}},
+					{Status: 409, Body: &ErrorBody{}},
+					{Status: 204},
+					{Body: ErrorBody{}},
+				},
+			},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	op := sw.Paths.Paths["/users/{id}"].Get
+	if !assert.NotNil(t, op) || !assert.NotNil(t, op.Responses) {
+		return
+	}
+	responses := op.Responses.StatusCodeResponses
+	assert.Contains(t, responses, 200)
+	notFound := responses[404]
+	assert.Equal(t, "User not found", notFound.Description)
+	assert.Equal(t, "#/definitions/generator.ErrorBody", notFound.Schema.Ref.String())
+	conflict := responses[409]
+	assert.Equal(t, "Conflict", conflict.Description)
+	assert.Equal(t, "#/definitions/generator.ErrorBody", conflict.Schema.Ref.String())
+	assert.Nil(t, responses[204].Schema)
+	if assert.NotNil(t, op.Responses.Default) {
+		assert.Equal(t, "#/definitions/generator.ErrorBody", op.Responses.Default.Schema.Ref.String())
+	}
+	assert.Contains(t, sw.Definitions, "generator.ErrorBody")
+	assert.Contains(t, sw.Definitions, "generator.ErrorDetail")
+}
+
+func TestEmitSuccessStatus(t *testing.T) {
+	reqType := reflect.TypeOf(SimpleStruct{})
+	respType := reflect.TypeOf(WithUUID{})
+	routes := map[string]RouteInfo{
+		"POST~/users": {
+			Method:     "POST",
+			Handler:    HandlerInfo{RequestType: &reqType, OutputType: &respType},
+			Parameters: HandlerParameters{SuccessStatus: 201},
+		},
+		"DELETE~/users/:id": {
+			Method:  "DELETE",
+			Handler: HandlerInfo{RequestType: &reqType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	created := sw.Paths.Paths["/users"].Post.Responses.StatusCodeResponses
+	assert.NotContains(t, created, 200)
+	if assert.Contains(t, created, 201) {
+		assert.Equal(t, "Created", created[201].Description)
+		assert.Equal(t, "#/definitions/generator.WithUUID", created[201].Schema.Ref.String())
+	}
+	deleted := sw.Paths.Paths["/users/{id}"].Delete.Responses.StatusCodeResponses
+	if assert.Contains(t, deleted, 204) {
+		assert.Nil(t, deleted[204].Schema)
+	}
+}
+
+type UploadReq struct {
+	ID      string                  `json:"id" param:"id,path"`
+	Title   string                  `json:"title"`
+	Avatar  *multipart.FileHeader   `json:"avatar"`
+	Photos  []*multipart.FileHeader `json:"photos"`
+	Session string                  `param:"session,cookie"`
+}
+
+func TestEmitOpenAPI3(t *testing.T) {
+	reqType := reflect.TypeOf(SimpleStruct{})
+	respType := reflect.TypeOf(WithUUID{})
+	listType := reflect.TypeOf(ArrayQueryReq{})
+	uploadType := reflect.TypeOf(UploadReq{})
+	routes := map[string]RouteInfo{
+		"POST~/users": {
+			Method:  "POST",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &respType},
+			Parameters: HandlerParameters{
+				Auth: []AuthType{{AuthTypeName: "basic", BasicAuth: &BasicAuthParams{}}},
+			},
+		},
+		"GET~/users": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &listType, OutputType: &respType},
+		},
+		"PUT~/users/:id/avatar": {
+			Method:  "PUT",
+			Handler: HandlerInfo{RequestType: &uploadType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	doc, err := gen.EmitOpenAPI3Definition(routes, OpenAPI30)
+	assert.NoError(t, err)
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Contains(t, doc.Components.Schemas, "generator.SimpleStruct")
+	assert.Equal(t, &openapi3.SecurityScheme{Type: "http", Scheme: "basic"}, doc.Components.SecuritySchemes["basic"])
+
+	create := doc.Paths["/users"].Post
+	if assert.NotNil(t, create) && assert.NotNil(t, create.RequestBody) {
+		body := create.RequestBody.Content["application/json"]
+		assert.Equal(t, "#/components/schemas/generator.SimpleStruct", body.Schema.Ref.String())
+		assert.Empty(t, create.Parameters)
+	}
+	if assert.Contains(t, create.Responses, "200") {
+		schema := create.Responses["200"].Content["application/json"].Schema
+		assert.Equal(t, "#/components/schemas/generator.WithUUID", schema.Ref.String())
+	}
+
+	list := doc.Paths["/users"].Get
+	if assert.NotNil(t, list) {
+		assert.Nil(t, list.RequestBody)
+		for _, param := range list.Parameters {
+			if param.Name == "ids" {
+				assert.Equal(t, "form", param.Style)
+				assert.False(t, *param.Explode)
+				assert.Equal(t, openapi.StringOrArray{"array"}, param.Schema.Type)
+			}
+		}
+	}
+
+	upload := doc.Paths["/users/{id}/avatar"].Put
+	if !assert.NotNil(t, upload) || !assert.NotNil(t, upload.RequestBody) {
+		return
+	}
+	locations := make(map[string]string)
+	for _, param := range upload.Parameters {
+		locations[param.Name] = param.In
+	}
+	assert.Equal(t, map[string]string{"id": "path", "session": "cookie"}, locations)
+	form, ok := upload.RequestBody.Content["multipart/form-data"]
+	if assert.True(t, ok) {
+		assert.Equal(t, "binary", form.Schema.Properties["avatar"].Format)
+		assert.Equal(t, "binary", form.Schema.Properties["photos"].Items.Schema.Format)
+		assert.Equal(t, "application/octet-stream", form.Encoding["avatar"].ContentType)
+		assert.Equal(t, "application/json", form.Encoding["request"].ContentType)
+	}
+
+	_, err = NewSwaggerGenerator().EmitOpenAPI3Definition(routes, "4.0")
+	assert.Error(t, err)
+}
+
+func TestEmitSpecOptions(t *testing.T) {
+	gen := NewSwaggerGenerator()
+	sw, err := gen.EmitOpenAPIDefinition(map[string]RouteInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, "API", sw.Info.Title)
+	assert.Equal(t, "1.0", sw.Info.Version)
+
+	gen = NewSwaggerGenerator()
+	gen.SetSpecOptions(SpecOptions{
+		Title:          "Users API",
+		Description:    "Users management",
+		Version:        "2.1",
+		TermsOfService: "https://example.com/terms",
+		Contact:        &ContactInfo{Name: "Team", Email: "team@example.com"},
+		License:        &LicenseInfo{Name: "MIT"},
+		Host:           "api.example.com",
+		BasePath:       "/v2",
+		Schemes:        []string{"https"},
+		ExternalDocs:   &ExternalDocs{URL: "https://example.com/docs"},
+		Tags:           []TagInfo{{Name: "Users", Description: "Users operations"}},
+	})
+	sw, err = gen.EmitOpenAPIDefinition(map[string]RouteInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Users API", sw.Info.Title)
+	assert.Equal(t, "Users management", sw.Info.Description)
+	assert.Equal(t, "2.1", sw.Info.Version)
+	assert.Equal(t, "https://example.com/terms", sw.Info.TermsOfService)
+	assert.Equal(t, "team@example.com", sw.Info.Contact.Email)
+	assert.Equal(t, "MIT", sw.Info.License.Name)
+	assert.Equal(t, "api.example.com", sw.Host)
+	assert.Equal(t, "/v2", sw.BasePath)
+	assert.Equal(t, []string{"https"}, sw.Schemes)
+	assert.Equal(t, "https://example.com/docs", sw.ExternalDocs.URL)
+	if assert.Equal(t, 1, len(sw.Tags)) {
+		assert.Equal(t, "Users operations", sw.Tags[0].Description)
+	}
+
+	doc, err := gen.EmitOpenAPI3Definition(map[string]RouteInfo{}, OpenAPI31)
+	assert.NoError(t, err)
+	assert.Equal(t, []openapi3.Server{{URL: "https://api.example.com/v2"}}, doc.Servers)
+
+	gen.SetSpecOptions(SpecOptions{Servers: []ServerInfo{{URL: "https://eu.example.com"}, {URL: "https://us.example.com"}}})
+	doc, err = gen.EmitOpenAPI3Definition(map[string]RouteInfo{}, OpenAPI31)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(doc.Servers))
+}
+
+func TestJSONToYAML(t *testing.T) {
+	res, err := JSONToYAML([]byte(`{"swagger": "2.0", "info": {"version": "1.0", "title": "API"}, "paths": {}, "count": 3, "ratio": 0.5, "flag": true, "none": null, "tags": ["b", "a"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `swagger: "2.0"
+info:
+  version: "1.0"
+  title: API
+paths: {}
+count: 3
+ratio: 0.5
+flag: true
+none: null
+tags:
+  - b
+  - a
+`, string(res))
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+func collidingTypes() (reflect.Type, reflect.Type) {
+	first := func() reflect.Type {
+		type UserRec struct {
+			Name string `json:"name"`
+		}
+		return reflect.TypeOf(UserRec{})
+	}
+	second := func() reflect.Type {
+		type UserRec struct {
+			Login string `json:"login"`
+		}
+		return reflect.TypeOf(UserRec{})
+	}
+	return first(), second()
+}
+
+func TestDefinitionNames(t *testing.T) {
+	pageType := reflect.TypeOf(Page[SimpleStruct]{})
+	assert.Equal(t, "generator.Page_generator.SimpleStruct", ShortDefinitionName(pageType))
+	assert.Equal(t, "github.com.AlhimicMan.goswag.generator.SimpleStruct", FullPathDefinitionName(reflect.TypeOf(SimpleStruct{})))
+
+	firstType, secondType := collidingTypes()
+	reqType := reflect.TypeOf(SimpleStruct{})
+	routes := map[string]RouteInfo{
+		"GET~/first": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &firstType},
+		},
+		"GET~/second": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &secondType},
+		},
+		"GET~/page": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &pageType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	firstRef := sw.Paths.Paths["/first"].Get.Responses.StatusCodeResponses[200].Schema.Ref
+	secondRef := sw.Paths.Paths["/second"].Get.Responses.StatusCodeResponses[200].Schema.Ref
+	assert.Equal(t, "#/definitions/generator.UserRec", firstRef.String())
+	assert.Equal(t, "#/definitions/github.com.AlhimicMan.goswag.generator.UserRec", secondRef.String())
+	assert.Contains(t, sw.Definitions["generator.UserRec"].Properties, "name")
+	assert.Contains(t, sw.Definitions["github.com.AlhimicMan.goswag.generator.UserRec"].Properties, "login")
+	page, ok := sw.Definitions["generator.Page_generator.SimpleStruct"]
+	if assert.True(t, ok) {
+		assert.Equal(t, "#/definitions/generator.SimpleStruct", page.Properties["items"].Items.Schema.Ref.String())
+	}
+
+	gen = NewSwaggerGenerator()
+	gen.SetDefinitionNamer(func(t reflect.Type) string {
+		return "Custom" + t.Name()
+	})
+	sw, err = gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	assert.Contains(t, sw.Definitions, "CustomUserRec")
+	assert.Contains(t, sw.Definitions, "github.com.AlhimicMan.goswag.generator.UserRec")
+	assert.Contains(t, sw.Definitions, "CustomSimpleStruct")
+}
+
+type ServerItems struct {
+	Server openapi3.Server `json:"server"`
+	Item   SimpleStruct    `json:"item"`
+}
+
+func TestDefinitionNamesOrder(t *testing.T) {
+	serverType := reflect.TypeOf(openapi3.Server{})
+	itemType := reflect.TypeOf(SimpleStruct{})
+	namer := func(t reflect.Type) string {
+		if t == serverType || t == itemType {
+			return "Item"
+		}
+		return ShortDefinitionName(t)
+	}
+	// Colliding name is assigned to type with the first full package path, whatever route refers it first
+	for _, outputs := range [][]reflect.Type{{serverType, itemType}, {itemType, serverType}} {
+		routes := map[string]RouteInfo{
+			"GET~/a": {Method: "GET", Handler: HandlerInfo{OutputType: &outputs[0]}},
+			"GET~/b": {Method: "GET", Handler: HandlerInfo{OutputType: &outputs[1]}},
+		}
+		gen := NewSwaggerGenerator()
+		gen.SetDefinitionNamer(namer)
+		sw, err := gen.EmitOpenAPIDefinition(routes)
+		assert.NoError(t, err)
+		assert.Contains(t, sw.Definitions["Item"].Properties, "Name")
+		assert.Contains(t, sw.Definitions["github.com.AlhimicMan.goswag.generator.openapi3.Server"].Properties, "url")
+		refs := map[reflect.Type]string{
+			serverType: "#/definitions/github.com.AlhimicMan.goswag.generator.openapi3.Server",
+			itemType:   "#/definitions/Item",
+		}
+		for i, path := range []string{"/a", "/b"} {
+			ref := sw.Paths.Paths[path].Get.Responses.StatusCodeResponses[200].Schema.Ref
+			assert.Equal(t, refs[outputs[i]], ref.String())
+		}
+	}
+
+	sGenerator := NewSchemaGenerator()
+	sGenerator.SetDefinitionNamer(namer)
+	defs, err := sGenerator.GetSchema(reflect.TypeOf(ServerItems{}))
+	assert.NoError(t, err)
+	assert.Contains(t, defs[definitionPrefix+"Item"].Properties, "Name")
+	def := defs[definitionPrefix+"generator.ServerItems"]
+	server, item := def.Properties["server"], def.Properties["item"]
+	assert.Equal(t, definitionPrefix+"github.com.AlhimicMan.goswag.generator.openapi3.Server", server.Ref.String())
+	assert.Equal(t, definitionPrefix+"Item", item.Ref.String())
+
+	firstType, secondType := collidingTypes()
+	pairType := reflect.StructOf([]reflect.StructField{
+		{Name: "First", Type: firstType, Tag: `json:"first"`},
+		{Name: "Second", Type: secondType, Tag: `json:"second"`},
+	})
+	defs, err = NewSchemaGenerator().GetSchema(pairType)
+	assert.NoError(t, err)
+	assert.Contains(t, defs[definitionPrefix+"generator.UserRec"].Properties, "name")
+	assert.Contains(t, defs[definitionPrefix+"github.com.AlhimicMan.goswag.generator.UserRec"].Properties, "login")
+}
+
+type Money struct {
+	Units int64
+	Nanos int32
+}
+
+// InvoiceRef is encoded by promoted uuid.UUID MarshalText
+type InvoiceRef struct {
+	uuid.UUID
+}
+
+// MoneyRow is encoded as object of promoted Money fields
+type MoneyRow struct {
+	Money
+}
+
+type Invoice struct {
+	ID    uuid.UUID  `json:"id"`
+	Total Money      `json:"total"`
+	Tax   *Money     `json:"tax"`
+	Ref   InvoiceRef `json:"ref"`
+	Row   MoneyRow   `json:"row"`
+}
+
+type InvoiceQuery struct {
+	ID  uuid.UUID `json:"id"`
+	Min Money     `json:"min"`
+}
+
+func TestEmitRegisteredTypes(t *testing.T) {
+	reqType := reflect.TypeOf(InvoiceQuery{})
+	respType := reflect.TypeOf(Invoice{})
+	routes := map[string]RouteInfo{
+		"GET~/invoices/:id": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &respType},
+			Parameters: HandlerParameters{
+				Responses: []ResponseParameters{{Status: 202, Body: Money{}}},
+			},
+		},
+	}
+	moneySchema := openapi.StringProperty()
+	moneySchema.Format = "decimal"
+	gen := NewSwaggerGenerator()
+	gen.SetTypeRegistry(NewTypeRegistry().Register(Money{}, *moneySchema))
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+
+	def := sw.Definitions["generator.Invoice"]
+	assert.Equal(t, openapi.StringOrArray{"string"}, def.Properties["id"].Type)
+	assert.Equal(t, "uuid", def.Properties["id"].Format)
+	assert.Equal(t, "decimal", def.Properties["total"].Format)
+	assert.Equal(t, "decimal", def.Properties["tax"].Format)
+	assert.Equal(t, "uuid", def.Properties["ref"].Format)
+	row := def.Properties["row"]
+	assert.Equal(t, "#/definitions/generator.MoneyRow", row.Ref.String())
+	assert.Contains(t, sw.Definitions["generator.MoneyRow"].Properties, "Units")
+	assert.NotContains(t, sw.Definitions, "generator.Money")
+
+	op := sw.Paths.Paths["/invoices/{id}"].Get
+	params := make(map[string]openapi.Parameter)
+	for _, param := range op.Parameters {
+		params[param.Name] = param
+	}
+	assert.Equal(t, "uuid", params["id"].Format)
+	assert.Equal(t, "string", params["min"].Type)
+	assert.Equal(t, "decimal", params["min"].Format)
+	assert.Equal(t, "decimal", op.Responses.StatusCodeResponses[202].Schema.Format)
+
+	sGenerator := NewSchemaGenerator()
+	sGenerator.SetTypeRegistry(NewTypeRegistry().Register(Money{}, *moneySchema))
+	defs, err := sGenerator.GetSchema(respType)
+	assert.NoError(t, err)
+	def = defs[definitionPrefix+"generator.Invoice"]
+	assert.Equal(t, "uuid", def.Properties["id"].Format)
+	assert.Equal(t, "decimal", def.Properties["total"].Format)
+	assert.Equal(t, "decimal", def.Properties["tax"].Format)
+	assert.Equal(t, "uuid", def.Properties["ref"].Format)
+	assert.Contains(t, defs[definitionPrefix+"generator.MoneyRow"].Properties, "Units")
+	assert.NotContains(t, defs, definitionPrefix+"generator.Money")
+}
+
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte("info"), nil
+}
+
+type WithStdlibTypes struct {
+	Data     []byte          `json:"data"`
+	Raw      json.RawMessage `json:"raw"`
+	Number   json.Number     `json:"number"`
+	Timeout  time.Duration   `json:"timeout"`
+	IP       net.IP          `json:"ip"`
+	Addr     netip.Addr      `json:"addr"`
+	Link     *url.URL        `json:"link"`
+	Amount   *big.Int        `json:"amount"`
+	Level    Level           `json:"level"`
+	Checksum [4]byte         `json:"checksum"`
+}
+
+func TestStdlibTypesSchemas(t *testing.T) {
+	expected := map[string][2]string{
+		"data":    {"string", "byte"},
+		"raw":     {"object", ""},
+		"number":  {"number", ""},
+		"timeout": {"integer", "int64"},
+		"ip":      {"string", "ip"},
+		"addr":    {"string", "ip"},
+		"link":    {"string", "uri"},
+		"amount":  {"integer", ""},
+		"level":   {"string", ""},
+	}
+	check := func(props openapi.SchemaProperties) {
+		for name, want := range expected {
+			prop, ok := props[name]
+			if !assert.True(t, ok, name) {
+				continue
+			}
+			assert.Equal(t, openapi.StringOrArray{want[0]}, prop.Type, name)
+			assert.Equal(t, want[1], prop.Format, name)
+		}
+		assert.True(t, props["raw"].AdditionalProperties.Allows)
+		assert.Equal(t, openapi.StringOrArray{"array"}, props["checksum"].Type)
+	}
+
+	defs, err := NewSchemaGenerator().GetSchema(reflect.TypeOf(WithStdlibTypes{}))
+	assert.NoError(t, err)
+	check(defs[definitionPrefix+"generator.WithStdlibTypes"].Properties)
+
+	respType := reflect.TypeOf(WithStdlibTypes{})
+	routes := map[string]RouteInfo{
+		"GET~/stdlib": {
+			Method:  "GET",
+			Handler: HandlerInfo{OutputType: &respType},
+		},
+	}
+	sw, err := NewSwaggerGenerator().EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sw.Definitions))
+	check(sw.Definitions["generator.WithStdlibTypes"].Properties)
+}
+
+type FlexibleID string
+
+func (FlexibleID) JSONSchema() openapi.Schema {
+	return openapi.Schema{SchemaProps: openapi.SchemaProps{
+		OneOf: []openapi.Schema{*openapi.StringProperty(), *openapi.Int64Property()},
+	}}
+}
+
+type CardPayment struct {
+	Number string `json:"number"`
+}
+
+type WirePayment struct {
+	IBAN string `json:"iban"`
+}
+
+type Payment struct {
+	Value interface{}
+}
+
+func (*Payment) JSONSchemaWith(resolve SchemaResolver) openapi.Schema {
+	return openapi.Schema{SchemaProps: openapi.SchemaProps{
+		OneOf: []openapi.Schema{resolve(CardPayment{}), resolve(&WirePayment{})},
+	}}
+}
+
+type Order struct {
+	ID       FlexibleID  `json:"id"`
+	Payment  Payment     `json:"payment"`
+	Shipping *time.Month `json:"shipping"`
+}
+
+type OrderQuery struct {
+	ID FlexibleID `json:"id" param:"id,path"`
+}
+
+func TestEmitSchemaProviders(t *testing.T) {
+	reqType := reflect.TypeOf(OrderQuery{})
+	respType := reflect.TypeOf(Order{})
+	routes := map[string]RouteInfo{
+		"GET~/orders/:id": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &respType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	gen.SetTypeRegistry(NewTypeRegistry().RegisterFunc(time.Month(0), func(SchemaResolver) openapi.Schema {
+		schema := openapi.StringProperty()
+		schema.Enum = []interface{}{"January", "December"}
+		return *schema
+	}))
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+
+	def := sw.Definitions["generator.Order"]
+	assert.Equal(t, 2, len(def.Properties["id"].OneOf))
+	payment := def.Properties["payment"]
+	if assert.Equal(t, 2, len(payment.OneOf)) {
+		assert.Equal(t, "#/definitions/generator.CardPayment", payment.OneOf[0].Ref.String())
+		assert.Equal(t, "#/definitions/generator.WirePayment", payment.OneOf[1].Ref.String())
+	}
+	assert.Contains(t, sw.Definitions, "generator.CardPayment")
+	assert.Contains(t, sw.Definitions, "generator.WirePayment")
+	assert.NotContains(t, sw.Definitions, "generator.Payment")
+	assert.Equal(t, 2, len(def.Properties["shipping"].Enum))
+
+	op := sw.Paths.Paths["/orders/{id}"].Get
+	if assert.Equal(t, 1, len(op.Parameters)) {
+		assert.Equal(t, "id", op.Parameters[0].Name)
+	}
+
+	sGenerator := NewSchemaGenerator()
+	sGenerator.SetTypeRegistry(gen.types)
+	defs, err := sGenerator.GetSchema(respType)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(defs[definitionPrefix+"generator.Order"].Properties["payment"].OneOf))
+	assert.Equal(t, 2, len(defs[definitionPrefix+"generator.Order"].Properties["shipping"].Enum))
+	assert.Contains(t, defs, definitionPrefix+"generator.WirePayment")
+}
+
+type TaggedAccount struct {
+	ID       int64     `json:"id,string"`
+	Name     string    `json:"name" validate:"required"`
+	Nickname string    `json:"nickname,omitempty"`
+	Parent   *int64    `json:"parent"`
+	Active   bool      `json:"active,string,omitempty"`
+	Balance  uint64    `json:"balance"`
+	Since    time.Time `json:"since" param:"since_date"`
+	Rank     int32     `json:",string,omitempty" validate:"min=1,oneof=1 2 3"`
+}
+
+func TestEmitJSONTagOptions(t *testing.T) {
+	respType := reflect.TypeOf(TaggedAccount{})
+	routes := map[string]RouteInfo{
+		"GET~/accounts": {
+			Method:  "GET",
+			Handler: HandlerInfo{OutputType: &respType},
+		},
+	}
+	emit := func(policy RequiredPolicy, int64AsString bool) openapi.Schema {
+		gen := NewSwaggerGenerator()
+		gen.SetRequiredPolicy(policy)
+		gen.SetInt64AsString(int64AsString)
+		sw, err := gen.EmitOpenAPIDefinition(routes)
+		assert.NoError(t, err)
+		return sw.Definitions["generator.TaggedAccount"]
+	}
+
+	def := emit(RequiredValidated, false)
+	assert.Equal(t, []string{"name"}, def.Required)
+	assert.Equal(t, openapi.StringOrArray{"string"}, def.Properties["id"].Type)
+	assert.Equal(t, "", def.Properties["id"].Format)
+	assert.Equal(t, "^-?[0-9]+$", def.Properties["id"].Pattern)
+	assert.Equal(t, openapi.StringOrArray{"string"}, def.Properties["active"].Type)
+	assert.Equal(t, "^(true|false)$", def.Properties["active"].Pattern)
+	rank := def.Properties["Rank"]
+	assert.Equal(t, openapi.StringOrArray{"string"}, rank.Type)
+	assert.Equal(t, "", rank.Format)
+	assert.Nil(t, rank.Minimum)
+	assert.Nil(t, rank.MinLength)
+	assert.Equal(t, []interface{}{"1", "2", "3"}, rank.Enum)
+	assert.Equal(t, openapi.StringOrArray{"integer"}, def.Properties["balance"].Type)
+	assert.Equal(t, openapi.StringOrArray{"integer"}, def.Properties["parent"].Type)
+	assert.Contains(t, def.Properties, "since")
+
+	def = emit(RequiredNotOmitted, false)
+	assert.Equal(t, []string{"id", "name", "parent", "balance", "since"}, def.Required)
+
+	def = emit(RequiredNonPointer, true)
+	assert.Equal(t, []string{"id", "name", "balance", "since"}, def.Required)
+	for _, name := range []string{"balance", "parent"} {
+		assert.Equal(t, openapi.StringOrArray{"string"}, def.Properties[name].Type, name)
+		assert.Equal(t, "", def.Properties[name].Format, name)
+	}
+	assert.Equal(t, "^-?[0-9]+$", def.Properties["parent"].Pattern)
+	assert.Equal(t, openapi.StringOrArray{"string"}, def.Properties["Rank"].Type)
+	assert.True(t, NewTypeRegistry().Int64String(reflect.TypeOf(time.Duration(0))))
+	assert.False(t, NewTypeRegistry().Int64String(reflect.TypeOf(int32(0))))
+	assert.False(t, NewTypeRegistry().Int64String(reflect.TypeOf(Level(0))))
+
+	defs, err := NewSchemaGenerator().GetSchema(respType)
+	assert.NoError(t, err)
+	props := defs[definitionPrefix+"generator.TaggedAccount"].Properties
+	assert.Contains(t, props, "nickname")
+	assert.Equal(t, openapi.StringOrArray{"string"}, props["id"].Type)
+	assert.Equal(t, "", props["Rank"].Format)
+	assert.Nil(t, props["Rank"].Minimum)
+	assert.Nil(t, props["Rank"].MinLength)
+}
+
+type AuditFields struct {
+	CreatedBy string `json:"created_by"`
+	UpdatedBy string `json:"updated_by"`
+	Note      string `json:"note"`
+}
+
+type Labels struct {
+	Note  string `json:"note"`
+	Color string `json:"color"`
+}
+
+type TaggedLabels struct {
+	Color string `json:"color"`
+}
+
+type Article struct {
+	AuditFields
+	*Labels
+	TaggedLabels `json:"tagged"`
+	Title        string `json:"title"`
+	UpdatedBy    int    `json:"updated_by"`
+}
+
+type LabeledPost struct {
+	AuditFields
+	*TaggedLabels
+	Title string `json:"title"`
+}
+
+func TestJSONFields(t *testing.T) {
+	names := make([]string, 0)
+	for _, field := range JSONFields(reflect.TypeOf(Article{})) {
+		names = append(names, GetFieldInfo(field).JSONName)
+	}
+	// note is ambiguous between embeds of the same depth, updated_by is shadowed by own field
+	assert.Equal(t, []string{"created_by", "color", "tagged", "title", "updated_by"}, names)
+
+	field := JSONFields(reflect.TypeOf(&Article{}))[1]
+	assert.Equal(t, []int{1, 1}, field.Index)
+}
+
+func TestEmitEmbeddedStructs(t *testing.T) {
+	respType := reflect.TypeOf(Article{})
+	routes := map[string]RouteInfo{
+		"GET~/articles": {
+			Method:  "GET",
+			Handler: HandlerInfo{OutputType: &respType},
+		},
+	}
+	sw, err := NewSwaggerGenerator().EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	def := sw.Definitions["generator.Article"]
+	assert.Equal(t, 5, len(def.Properties))
+	assert.Equal(t, openapi.StringOrArray{"integer"}, def.Properties["updated_by"].Type)
+	tagged := def.Properties["tagged"]
+	assert.Equal(t, "#/definitions/generator.TaggedLabels", tagged.Ref.String())
+	assert.NotContains(t, sw.Definitions, "generator.AuditFields")
+
+	postType := reflect.TypeOf(LabeledPost{})
+	routes["GET~/posts"] = RouteInfo{
+		Method:  "GET",
+		Handler: HandlerInfo{OutputType: &postType},
+	}
+	gen := NewSwaggerGenerator()
+	gen.SetEmbedsAsAllOf(true)
+	sw, err = gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	// Embeds with shadowed or ambiguous fields are flattened
+	def = sw.Definitions["generator.Article"]
+	assert.Equal(t, 0, len(def.AllOf))
+	assert.Equal(t, 5, len(def.Properties))
+	assert.Equal(t, openapi.StringOrArray{"integer"}, def.Properties["updated_by"].Type)
+	def = sw.Definitions["generator.LabeledPost"]
+	if assert.Equal(t, 3, len(def.AllOf)) {
+		assert.Equal(t, "#/definitions/generator.AuditFields", def.AllOf[0].Ref.String())
+		assert.Equal(t, "#/definitions/generator.TaggedLabels", def.AllOf[1].Ref.String())
+		assert.Equal(t, 1, len(def.AllOf[2].Properties))
+		assert.Contains(t, def.AllOf[2].Properties, "title")
+	}
+	assert.Contains(t, sw.Definitions, "generator.AuditFields")
+
+	defs, err := NewSchemaGenerator().GetSchema(respType)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(defs[definitionPrefix+"generator.Article"].Properties))
+}
+
+type Comment struct {
+	Text    string    `json:"text"`
+	Replies []Comment `json:"replies"`
+}
+
+type OrgNode struct {
+	Name   string   `json:"name"`
+	Parent *OrgNode `json:"parent"`
+	Team   *OrgTeam `json:"team"`
+}
+
+type OrgTeam struct {
+	Lead    *OrgNode            `json:"lead"`
+	Members map[string]*OrgNode `json:"members"`
+}
+
+type Forest []Forest
+
+type Attributes map[string]Attributes
+
+type Catalog struct {
+	Forest     Forest     `json:"forest"`
+	Attributes Attributes `json:"attributes"`
+	Comments   []Comment  `json:"comments"`
+	Root       OrgNode    `json:"root"`
+}
+
+func TestEmitRecursiveTypes(t *testing.T) {
+	respType := reflect.TypeOf(Catalog{})
+	routes := map[string]RouteInfo{
+		"GET~/catalog": {
+			Method:  "GET",
+			Handler: HandlerInfo{OutputType: &respType},
+		},
+	}
+	sw, err := NewSwaggerGenerator().EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+
+	ref := func(schema openapi.Schema) string {
+		return schema.Ref.String()
+	}
+	comment := sw.Definitions["generator.Comment"]
+	assert.Equal(t, "#/definitions/generator.Comment", ref(*comment.Properties["replies"].Items.Schema))
+
+	node := sw.Definitions["generator.OrgNode"]
+	assert.Equal(t, "#/definitions/generator.OrgNode", ref(node.Properties["parent"]))
+	assert.Equal(t, "#/definitions/generator.OrgTeam", ref(node.Properties["team"]))
+	team := sw.Definitions["generator.OrgTeam"]
+	assert.Equal(t, "#/definitions/generator.OrgNode", ref(team.Properties["lead"]))
+	assert.Equal(t, "#/definitions/generator.OrgNode", ref(*team.Properties["members"].AdditionalProperties.Schema))
+
+	catalog := sw.Definitions["generator.Catalog"]
+	assert.Equal(t, "#/definitions/generator.Forest", ref(catalog.Properties["forest"]))
+	forest := sw.Definitions["generator.Forest"]
+	assert.Equal(t, openapi.StringOrArray{"array"}, forest.Type)
+	assert.Equal(t, "#/definitions/generator.Forest", ref(*forest.Items.Schema))
+	assert.Equal(t, "#/definitions/generator.Attributes", ref(catalog.Properties["attributes"]))
+	attributes := sw.Definitions["generator.Attributes"]
+	assert.Equal(t, "#/definitions/generator.Attributes", ref(*attributes.AdditionalProperties.Schema))
+	assert.Equal(t, 6, len(sw.Definitions))
+
+	defs, err := NewSchemaGenerator().GetSchema(respType)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(defs))
+	legacyForest := defs[definitionPrefix+"generator.Forest"]
+	assert.Equal(t, "#/definitions/generator.Forest", ref(*legacyForest.Items.Schema))
+	legacyComment := defs[definitionPrefix+"generator.Comment"]
+	assert.Equal(t, "#/definitions/generator.Comment", ref(*legacyComment.Properties["replies"].Items.Schema))
+}
+
+type Expression struct{}
+
+func (Expression) JSONSchemaWith(resolve SchemaResolver) openapi.Schema {
+	operands := resolve([]Expression{})
+	return openapi.Schema{SchemaProps: openapi.SchemaProps{
+		OneOf: []openapi.Schema{*openapi.StringProperty(), operands},
+	}}
+}
+
+func TestEmitRecursiveSchemaProvider(t *testing.T) {
+	respType := reflect.TypeOf(Expression{})
+	routes := map[string]RouteInfo{
+		"GET~/expression": {
+			Method:  "GET",
+			Handler: HandlerInfo{OutputType: &respType},
+		},
+	}
+	sw, err := NewSwaggerGenerator().EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+	op := sw.Paths.Paths["/expression"].Get
+	assert.Equal(t, "#/definitions/generator.Expression", op.Responses.StatusCodeResponses[200].Schema.Ref.String())
+	expression := sw.Definitions["generator.Expression"]
+	if assert.Equal(t, 2, len(expression.OneOf)) {
+		assert.Equal(t, "#/definitions/generator.Expression", expression.OneOf[1].Items.Schema.Ref.String())
+	}
+
+	defs, err := NewSchemaGenerator().GetSchema(respType)
+	assert.NoError(t, err)
+	expression = defs[definitionPrefix+"generator.Expression"]
+	if assert.Equal(t, 2, len(expression.OneOf)) {
+		assert.Equal(t, "#/definitions/generator.Expression", expression.OneOf[1].Items.Schema.Ref.String())
+	}
+	defs, err = NewSchemaGenerator().GetSchema(reflect.TypeOf(struct {
+		Filter Expression `json:"filter"`
+	}{}))
+	assert.NoError(t, err)
+	assert.Contains(t, defs, definitionPrefix+"generator.Expression")
+}
+
+type DocumentedUser struct {
+	ID       int64      `json:"id" doc:"User identifier" example:"42" readOnly:"true"`
+	Email    string     `json:"email" doc:"Contact email" format:"email" maxLength:"64"`
+	Role     string     `json:"role" enum:"admin, user" default:"user"`
+	Tags     []string   `json:"tags" enum:"a,b" example:"a,b" maxItems:"5"`
+	Password string     `json:"password" writeOnly:"true" minLength:"8"`
+	Login    string     `json:"login" deprecated:"true"`
+	Score    float64    `json:"score" minimum:"0" maximum:"100" multipleOf:"0.5"`
+	Manager  *SimpleRec `json:"manager" doc:"User manager"`
+}
+
+type SimpleRec struct {
+	Name string `json:"name"`
+}
+
+type DocumentedQuery struct {
+	ID    string   `json:"id" param:"id,path" doc:"Account" pattern:"^[a-z]+$"`
+	Limit int      `json:"limit" param:"limit,query" doc:"Page size" example:"20" minimum:"1"`
+	Roles []string `json:"roles" param:"roles,query" enum:"admin,user"`
+	Old   bool     `json:"old" param:"old,query" deprecated:"true" default:"false"`
+}
+
+func TestEmitDocTags(t *testing.T) {
+	reqType := reflect.TypeOf(DocumentedQuery{})
+	respType := reflect.TypeOf(DocumentedUser{})
+	routes := map[string]RouteInfo{
+		"GET~/accounts/:id/users": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &respType},
+		},
+	}
+	sw, err := NewSwaggerGenerator().EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+
+	props := sw.Definitions["generator.DocumentedUser"].Properties
+	assert.Equal(t, "User identifier", props["id"].Description)
+	assert.Equal(t, int64(42), props["id"].Example)
+	assert.True(t, props["id"].ReadOnly)
+	assert.Equal(t, "email", props["email"].Format)
+	assert.Equal(t, int64(64), *props["email"].MaxLength)
+	assert.Equal(t, []interface{}{"admin", "user"}, props["role"].Enum)
+	assert.Equal(t, "user", props["role"].Default)
+	assert.Equal(t, []interface{}{"a", "b"}, props["tags"].Items.Schema.Enum)
+	assert.Equal(t, []interface{}{"a", "b"}, props["tags"].Example)
+	assert.Equal(t, int64(5), *props["tags"].MaxItems)
+	writeOnly, _ := props["password"].Extensions.GetBool(openapi3.WriteOnlyExtension)
+	assert.True(t, writeOnly)
+	deprecated, _ := props["login"].Extensions.GetBool(openapi3.DeprecatedExtension)
+	assert.True(t, deprecated)
+	assert.Equal(t, 0.5, *props["score"].MultipleOf)
+	assert.Equal(t, 100.0, *props["score"].Maximum)
+	manager := props["manager"]
+	assert.Equal(t, "User manager", manager.Description)
+	if assert.Equal(t, 1, len(manager.AllOf)) {
+		assert.Equal(t, "#/definitions/generator.SimpleRec", manager.AllOf[0].Ref.String())
+	}
+	assert.Nil(t, sw.Definitions["generator.SimpleRec"].Properties["name"].Enum)
+
+	params := make(map[string]openapi.Parameter)
+	for _, param := range sw.Paths.Paths["/accounts/{id}/users"].Get.Parameters {
+		params[param.Name] = param
+	}
+	assert.Equal(t, "Account", params["id"].Description)
+	assert.Equal(t, "^[a-z]+$", params["id"].Pattern)
+	assert.Equal(t, int64(20), params["limit"].Example)
+	assert.Equal(t, 1.0, *params["limit"].Minimum)
+	assert.Equal(t, []interface{}{"admin", "user"}, params["roles"].Items.Enum)
+	assert.Equal(t, false, params["old"].Default)
+
+	doc, err := openapi3.FromSwagger(sw, openapi3.Version30)
+	assert.NoError(t, err)
+	schema := doc.Components.Schemas["generator.DocumentedUser"]
+	assert.Equal(t, true, schema.Properties["login"].ExtraProps["deprecated"])
+	assert.Equal(t, true, schema.Properties["password"].ExtraProps["writeOnly"])
+	assert.NotContains(t, schema.Properties["password"].Extensions, openapi3.WriteOnlyExtension)
+	for _, param := range doc.Paths["/accounts/{id}/users"].Get.Parameters {
+		assert.Equal(t, param.Name == "old", param.Deprecated, param.Name)
+	}
+}
+
+// DocumentedOrder is order placed by customer
+type DocumentedOrder struct {
+	// ID is order identifier
+	ID int64 `json:"id"`
+	// Total is ignored, doc tag takes precedence
+	Total  float64 `json:"total" doc:"Order total"`
+	Status string  `json:"status"` // Status of delivery
+	DocumentedMeta
+}
+
+// DocumentedMeta is order metadata
+type DocumentedMeta struct {
+	// Source is order channel
+	Source string `json:"source"`
+}
+
+// GetDocumentedOrder returns order by id. Orders of other customers are not found.
+//
+// Deleted orders are returned with status "deleted".
+func GetDocumentedOrder(ctx context.Context, req SimpleRec) (DocumentedOrder, error) {
+	return DocumentedOrder{}, nil
+}
+
+// testDocComments returns doc comments of test file declarations
+func testDocComments(t *testing.T) *DocComments {
+	docs := NewDocComments()
+	err := docs.AddPackage("github.com/AlhimicMan/goswag/generator", "generator_test.go")
+	assert.NoError(t, err)
+	return docs
+}
+
+func TestEmitDocComments(t *testing.T) {
+	docs := testDocComments(t)
+	respType := reflect.TypeOf(DocumentedOrder{})
+	funcName := runtime.FuncForPC(reflect.ValueOf(GetDocumentedOrder).Pointer()).Name()
+	routes := map[string]RouteInfo{
+		"GET~/orders": {
+			Method:  "GET",
+			Handler: HandlerInfo{FuncName: funcName, OutputType: &respType},
+		},
+		"GET~/orders/summary": {
+			Method:     "GET",
+			Handler:    HandlerInfo{FuncName: funcName, OutputType: &respType},
+			Parameters: HandlerParameters{Summary: "Get order summary", Description: "Explicit description"},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	gen.SetDocComments(docs)
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+
+	op := sw.Paths.Paths["/orders"].Get
+	assert.Equal(t, "GetDocumentedOrder returns order by id.", op.Summary)
+	assert.True(t, strings.HasSuffix(op.Description, `Deleted orders are returned with status "deleted".`))
+	op = sw.Paths.Paths["/orders/summary"].Get
+	assert.Equal(t, "Get order summary", op.Summary)
+	assert.Equal(t, "Explicit description", op.Description)
+
+	def := sw.Definitions["generator.DocumentedOrder"]
+	assert.Equal(t, "DocumentedOrder is order placed by customer", def.Description)
+	assert.Equal(t, "ID is order identifier", def.Properties["id"].Description)
+	assert.Equal(t, "Order total", def.Properties["total"].Description)
+	assert.Equal(t, "Status of delivery", def.Properties["status"].Description)
+	assert.Equal(t, "Source is order channel", def.Properties["source"].Description)
+}
+
+type ShipmentState string
+
+const (
+	ShipmentPending   ShipmentState = "pending"
+	ShipmentShipped   ShipmentState = "shipped"
+	ShipmentDelivered ShipmentState = "delivered"
+)
+
+type ShipmentPriority int
+
+const (
+	PriorityLow ShipmentPriority = iota + 1
+	PriorityNormal
+	PriorityHigh
+)
+
+type ShipmentLimit int
+
+const DefaultShipmentLimit ShipmentLimit = 20
+
+const (
+	MaxShipmentLimit ShipmentLimit = 100
+	ShipmentTimeout                = 30
+)
+
+type ShipmentCarrier string
+
+func (ShipmentCarrier) Enum() []interface{} {
+	return []interface{}{"ups", "dhl"}
+}
+
+type Shipment struct {
+	State    ShipmentState    `json:"state"`
+	Priority ShipmentPriority `json:"priority"`
+	Carrier  *ShipmentCarrier `json:"carrier"`
+	History  []ShipmentState  `json:"history"`
+}
+
+type ShipmentQuery struct {
+	ID      string          `json:"id" param:"id,path"`
+	State   ShipmentState   `json:"state" param:"state,path"`
+	Carrier ShipmentCarrier `json:"carrier" param:"carrier,query"`
+	States  []ShipmentState `json:"states" param:"states,query"`
+}
+
+func TestEmitEnums(t *testing.T) {
+	assert.Equal(t, []interface{}{"ups", "dhl"}, EnumValues(reflect.TypeOf(ShipmentCarrier("")), nil))
+	assert.Nil(t, EnumValues(reflect.TypeOf(ShipmentState("")), nil))
+
+	docs := testDocComments(t)
+	assert.Equal(t, []interface{}{"pending", "shipped", "delivered"}, EnumValues(reflect.TypeOf(ShipmentState("")), docs))
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(3)}, EnumValues(reflect.TypeOf(ShipmentPriority(0)), docs))
+	assert.Nil(t, EnumValues(reflect.TypeOf(ShipmentLimit(0)), docs))
+
+	reqType := reflect.TypeOf(ShipmentQuery{})
+	respType := reflect.TypeOf(Shipment{})
+	routes := map[string]RouteInfo{
+		"GET~/shipments/:id/:state": {
+			Method:  "GET",
+			Handler: HandlerInfo{RequestType: &reqType, OutputType: &respType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	gen.SetDocComments(docs)
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+
+	def := sw.Definitions["generator.Shipment"]
+	assert.Equal(t, []interface{}{"pending", "shipped", "delivered"}, def.Properties["state"].Enum)
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(3)}, def.Properties["priority"].Enum)
+	assert.Equal(t, []interface{}{"ups", "dhl"}, def.Properties["carrier"].Enum)
+	assert.Equal(t, []interface{}{"pending", "shipped", "delivered"}, def.Properties["history"].Items.Schema.Enum)
+
+	params := make(map[string]openapi.Parameter)
+	for _, param := range sw.Paths.Paths["/shipments/{id}/{state}"].Get.Parameters {
+		params[param.Name] = param
+	}
+	assert.Nil(t, params["id"].Enum)
+	assert.Equal(t, []interface{}{"pending", "shipped", "delivered"}, params["state"].Enum)
+	assert.Equal(t, []interface{}{"ups", "dhl"}, params["carrier"].Enum)
+	assert.Equal(t, []interface{}{"pending", "shipped", "delivered"}, params["states"].Items.Enum)
+}
+
+type TrackedEvent interface {
+	EventName() string
+}
+
+type ClickEvent struct {
+	Type   string `json:"type"`
+	Button string `json:"button"`
+}
+
+func (ClickEvent) EventName() string { return "click" }
+
+type ScrollEvent struct {
+	Offset int `json:"offset"`
+}
+
+func (*ScrollEvent) EventName() string { return "scroll" }
+
+type EventBatch struct {
+	Primary TrackedEvent   `json:"primary"`
+	Events  []TrackedEvent `json:"events"`
+}
+
+func TestEmitUnions(t *testing.T) {
+	types := NewTypeRegistry()
+	assert.Error(t, types.RegisterUnion(ClickEvent{}, "type", nil))
+	assert.Error(t, types.RegisterUnion((*TrackedEvent)(nil), "type", map[string]interface{}{"scroll": ScrollEvent{}}))
+	err := types.RegisterUnion((*TrackedEvent)(nil), "type", map[string]interface{}{
+		"click":  ClickEvent{},
+		"scroll": &ScrollEvent{},
+	})
+	assert.NoError(t, err)
+
+	respType := reflect.TypeOf(EventBatch{})
+	routes := map[string]RouteInfo{
+		"GET~/events": {
+			Method:  "GET",
+			Handler: HandlerInfo{OutputType: &respType},
+		},
+	}
+	gen := NewSwaggerGenerator()
+	gen.SetTypeRegistry(types)
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+
+	batch := sw.Definitions["generator.EventBatch"]
+	primary := batch.Properties["primary"]
+	assert.Equal(t, "#/definitions/generator.TrackedEvent", primary.Ref.String())
+	assert.Equal(t, "#/definitions/generator.TrackedEvent", batch.Properties["events"].Items.Schema.Ref.String())
+
+	// Swagger 2.0 variants extend base definition with discriminator
+	event := sw.Definitions["generator.TrackedEvent"]
+	assert.Equal(t, "type", event.Discriminator)
+	assert.Equal(t, []string{"type"}, event.Required)
+	assert.Equal(t, []interface{}{"click", "scroll"}, event.Properties["type"].Enum)
+	assert.Empty(t, event.OneOf)
+	assert.NotContains(t, event.Extensions, openapi3.DiscriminatorMappingExtension)
+	click := sw.Definitions["generator.ClickEvent"]
+	if assert.Len(t, click.AllOf, 2) {
+		assert.Equal(t, "#/definitions/generator.TrackedEvent", click.AllOf[0].Ref.String())
+		assert.Equal(t, []interface{}{"click"}, click.AllOf[1].Properties["type"].Enum)
+		assert.Contains(t, click.AllOf[1].Required, "type")
+	}
+	assert.Equal(t, "click", click.Extensions["x-discriminator-value"])
+	scroll := sw.Definitions["generator.ScrollEvent"]
+	if assert.Len(t, scroll.AllOf, 2) {
+		assert.Equal(t, "#/definitions/generator.TrackedEvent", scroll.AllOf[0].Ref.String())
+	}
+	assert.Equal(t, "scroll", scroll.Extensions["x-discriminator-value"])
+
+	doc, err := gen.EmitOpenAPI3Definition(routes, OpenAPI30)
+	assert.NoError(t, err)
+	oneOf := doc.Components.Schemas["generator.TrackedEvent"].OneOf
+	if assert.Len(t, oneOf, 2) {
+		assert.Equal(t, "#/components/schemas/generator.ClickEvent", oneOf[0].Ref.String())
+		assert.Equal(t, "#/components/schemas/generator.ScrollEvent", oneOf[1].Ref.String())
+	}
+	click = doc.Components.Schemas["generator.ClickEvent"]
+	assert.Empty(t, click.AllOf)
+	assert.Equal(t, []interface{}{"click"}, click.Properties["type"].Enum)
+	scroll = doc.Components.Schemas["generator.ScrollEvent"]
+	assert.Equal(t, []string{"type"}, scroll.Required)
+	eventJSON, err := json.Marshal(doc.Components.Schemas["generator.TrackedEvent"])
+	assert.NoError(t, err)
+	assert.Contains(t, string(eventJSON), `"discriminator":{"mapping":{"click":"#/components/schemas/generator.ClickEvent","scroll":"#/components/schemas/generator.ScrollEvent"},"propertyName":"type"}`)
+	assert.NotContains(t, string(eventJSON), openapi3.DiscriminatorMappingExtension)
+}
+
+type MaybeValue[T any] struct {
+	Value T
+	Set   bool
+}
+
+func (MaybeValue[T]) OptionalType() (reflect.Type, bool) {
+	return reflect.TypeOf((*T)(nil)).Elem(), false
+}
+
+func (m MaybeValue[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Value)
+}
+
+type NullValue[T any] struct {
+	Value T
+	Null  bool
+}
+
+func (NullValue[T]) OptionalType() (reflect.Type, bool) {
+	return reflect.TypeOf((*T)(nil)).Elem(), true
+}
+
+type ProfilePatch struct {
+	ID       string                    `json:"id" param:"id,path"`
+	Version  MaybeValue[int]           `json:"version" param:"version,query"`
+	Name     MaybeValue[string]        `json:"name" validate:"required"`
+	Nickname NullValue[string]         `json:"nickname"`
+	Manager  NullValue[SimpleRec]      `json:"manager"`
+	Tags     MaybeValue[[]string]      `json:"tags"`
+	State    MaybeValue[ShipmentState] `json:"state"`
+	Email    string                    `json:"email"`
+}
+
+func TestEmitOptionalFields(t *testing.T) {
+	reqType := reflect.TypeOf(ProfilePatch{})
+	routes := map[string]RouteInfo{
+		"PATCH~/profiles/:id": {
+			Method:  "PATCH",
+			Handler: HandlerInfo{RequestType: &reqType},
+		},
+	}
+	docs := testDocComments(t)
+	gen := NewSwaggerGenerator()
+	gen.SetRequiredPolicy(RequiredNotOmitted)
+	gen.SetDocComments(docs)
+	sw, err := gen.EmitOpenAPIDefinition(routes)
+	assert.NoError(t, err)
+
+	op := sw.Paths.Paths["/profiles/{id}"].Patch
+	assert.Equal(t, []string{"application/json", MergePatchContentType}, op.Consumes)
+	params := make(map[string]openapi.Parameter)
+	for _, param := range op.Parameters {
+		params[param.Name] = param
+	}
+	assert.Equal(t, "query", params["version"].In)
+	assert.Equal(t, "integer", params["version"].Type)
+	assert.False(t, params["version"].Required)
+
+	def := sw.Definitions["generator.ProfilePatch"]
+	assert.Equal(t, []string{"email"}, def.Required)
+	assert.Equal(t, openapi.StringOrArray{"string"}, def.Properties["name"].Type)
+	nickname := def.Properties["nickname"]
+	assert.Equal(t, openapi.StringOrArray{"string"}, nickname.Type)
+	assert.Equal(t, true, nickname.Extensions["x-nullable"])
+	manager := def.Properties["manager"]
+	assert.Equal(t, "", manager.Ref.String())
+	if assert.Equal(t, 1, len(manager.AllOf)) {
+		assert.Equal(t, "#/definitions/generator.SimpleRec", manager.AllOf[0].Ref.String())
+	}
+	assert.Equal(t, true, manager.Extensions["x-nullable"])
+	assert.Equal(t, openapi.StringOrArray{"array"}, def.Properties["tags"].Type)
+	assert.Equal(t, []interface{}{"pending", "shipped", "delivered"}, def.Properties["state"].Enum)
+	assert.NotContains(t, def.Properties["email"].Extensions, "x-nullable")
+	_, ok := sw.Definitions["generator.SimpleRec"]
+	assert.True(t, ok)
+
+	doc, err := gen.EmitOpenAPI3Definition(routes, OpenAPI31)
+	assert.NoError(t, err)
+	nicknameJSON, err := json.Marshal(doc.Components.Schemas["generator.ProfilePatch"].Properties["nickname"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": ["string", "null"]}`, string(nicknameJSON))
+	assert.Contains(t, doc.Paths["/profiles/{id}"].Patch.RequestBody.Content, MergePatchContentType)
+}
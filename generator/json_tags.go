@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"reflect"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+// stringNumberPatterns describe numbers and booleans encoded as JSON strings
+var stringNumberPatterns = map[string]string{
+	"integer": "^-?[0-9]+$",
+	"number":  "^-?[0-9]+(\\.[0-9]+)?([eE][-+]?[0-9]+)?$",
+	"boolean": "^(true|false)$",
+}
+
+// RequiredPolicy defines which definition properties are listed as required
+type RequiredPolicy int
+
+const (
+	// RequiredValidated marks as required only fields with validate:"required" tag
+	RequiredValidated RequiredPolicy = iota
+	// RequiredNotOmitted also marks fields without omitempty option, they are always present in encoded JSON
+	RequiredNotOmitted
+	// RequiredNonPointer also marks non pointer fields without omitempty option, pointer fields may be null
+	RequiredNonPointer
+)
+
+// SetRequiredPolicy sets policy of required definition properties, RequiredValidated by default
+func (s *SwaggerGenerator) SetRequiredPolicy(policy RequiredPolicy) {
+	s.requiredPolicy = policy
+}
+
+// SetInt64AsString enables rendering of 64-bit integers as strings for JavaScript clients, which lose precision
+// of numbers above 2^53. Application must encode such values as strings, RouteWrapper does it in this mode
+func (s *SwaggerGenerator) SetInt64AsString(enabled bool) {
+	s.int64AsString = enabled
+}
+
+// Int64String reports if values of type are 64-bit integers rendered as strings in int64 as string mode.
+// Types with registered, provided or custom JSON encoding keep their schemas
+func (r *TypeRegistry) Int64String(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+	default:
+		return false
+	}
+	if _, ok := r.Schema(t); ok || r.hasProvider(t) {
+		return false
+	}
+	return t == durationType || builtinSchema(t) == nil
+}
+
+// isRequired reports if field is required by policy. Fields required by validation rules are always required,
+// optional fields are never required
+func (p RequiredPolicy) isRequired(field reflect.StructField, fInfo *fieldInfo, validated bool) bool {
+	if isOptional(field.Type) {
+		// Optional fields can be absent
+		return false
+	}
+	if validated {
+		return true
+	}
+	switch p {
+	case RequiredNotOmitted:
+		return !fInfo.OmitEmpty
+	case RequiredNonPointer:
+		return !fInfo.OmitEmpty && field.Type.Kind() != reflect.Ptr
+	}
+	return false
+}
+
+// stringNumberSchema converts schema of number or boolean to schema of its JSON string encoding. Numeric
+// format and limits do not apply to strings, encoded value is described by pattern
+func stringNumberSchema(schema *openapi.Schema) {
+	if len(schema.Type) != 1 {
+		return
+	}
+	pattern, ok := stringNumberPatterns[schema.Type[0]]
+	if !ok {
+		return
+	}
+	if schema.Type[0] == "integer" && schema.Minimum != nil && *schema.Minimum >= 0 {
+		pattern = "^[0-9]+$"
+	}
+	schema.Type = []string{"string"}
+	schema.Format = ""
+	schema.Pattern = pattern
+	schema.Minimum = nil
+	schema.Maximum = nil
+	schema.ExclusiveMinimum = false
+	schema.ExclusiveMaximum = false
+	schema.MultipleOf = nil
+	schema.Enum = stringValues(schema.Enum)
+}
+
+// numberKind reports if values of type or its pointer element type are numbers
+func numberKind(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// stringEncoded reports if field value is encoded as JSON string by ",string" tag option.
+// Option applies only to fields of numeric and boolean types
+func stringEncoded(field reflect.StructField, fInfo *fieldInfo) bool {
+	if !fInfo.AsString {
+		return false
+	}
+	fType := field.Type
+	if fType.Kind() == reflect.Ptr {
+		fType = fType.Elem()
+	}
+	switch fType.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+// DefinitionNamer returns definition name of struct type. Names must be unique for correct spec,
+// generator disambiguates colliding names
+type DefinitionNamer func(t reflect.Type) string
+
+var (
+	pkgDirsRe        = regexp.MustCompile(`[\w.\-~]+/`)
+	invalidNameRe    = regexp.MustCompile(`[^A-Za-z0-9._\-]+`)
+	anonymousDefName = "Object"
+)
+
+// ShortDefinitionName names definition by last package path segment and type name, like users.UserRec.
+// Type arguments of generic types are named the same way: Page[users.UserRec] becomes models.Page_users.UserRec
+func ShortDefinitionName(t reflect.Type) string {
+	pParts := strings.Split(t.PkgPath(), "/")
+	lastPart := pParts[len(pParts)-1]
+	if len(lastPart) > 0 {
+		lastPart += "."
+	}
+	return sanitizeDefinitionName(lastPart + pkgDirsRe.ReplaceAllString(t.Name(), ""))
+}
+
+// FullPathDefinitionName names definition by full package path and type name,
+// like github.com.org.service.users.UserRec
+func FullPathDefinitionName(t reflect.Type) string {
+	name := t.Name()
+	if t.PkgPath() != "" {
+		name = t.PkgPath() + "." + name
+	}
+	return sanitizeDefinitionName(strings.ReplaceAll(name, "/", "."))
+}
+
+// sanitizeDefinitionName replaces characters not allowed in component names, like brackets of generic types
+func sanitizeDefinitionName(name string) string {
+	return strings.Trim(invalidNameRe.ReplaceAllString(name, "_"), "_")
+}
+
+// SetDefinitionNamer sets naming strategy of definitions, ShortDefinitionName by default
+func (s *SwaggerGenerator) SetDefinitionNamer(namer DefinitionNamer) {
+	s.namer = namer
+}
+
+// definitionNames assigns unique definition names to types. Name colliding with name of another type
+// is replaced with full path name, then numbered
+type definitionNames struct {
+	namer DefinitionNamer
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+func newDefinitionNames(namer DefinitionNamer) *definitionNames {
+	if namer == nil {
+		namer = ShortDefinitionName
+	}
+	return &definitionNames{
+		namer: namer,
+		names: make(map[reflect.Type]string),
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// assign names types sorted by full package path, so colliding names are disambiguated the same way
+// whatever order types were referenced in. Types with the same full path keep their order
+func (n *definitionNames) assign(types []reflect.Type) {
+	sorted := make([]reflect.Type, len(types))
+	copy(sorted, types)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return FullPathDefinitionName(sorted[i]) < FullPathDefinitionName(sorted[j])
+	})
+	for _, t := range sorted {
+		n.name(t)
+	}
+}
+
+// name returns definition name of type, name is assigned on first call
+func (n *definitionNames) name(t reflect.Type) string {
+	if name, ok := n.names[t]; ok {
+		return name
+	}
+	candidates := []string{n.namer(t), FullPathDefinitionName(t)}
+	name := ""
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if _, taken := n.types[candidate]; !taken {
+			name = candidate
+			break
+		}
+	}
+	if name == "" {
+		base := candidates[0]
+		if base == "" {
+			base = candidates[1]
+		}
+		if base == "" {
+			base = anonymousDefName
+		}
+		name = base
+		for i := 2; ; i++ {
+			if _, taken := n.types[name]; !taken {
+				break
+			}
+			name = base + strconv.Itoa(i)
+		}
+	}
+	n.names[t] = name
+	n.types[name] = t
+	return name
+}
+
+// definitionName returns unique definition name of type
+func (s *SwaggerGenerator) definitionName(t reflect.Type) string {
+	return s.names.name(t)
+}
+
+// definitionRef adds type to definitions and returns reference to it
+func (s *SwaggerGenerator) definitionRef(t reflect.Type) *openapi.Schema {
+	s.addDefinition(t)
+	return openapi.RefProperty(definitionPrefix + s.definitionName(t))
+}
+
+// addDefinition queues struct type for definitions processing
+func (s *SwaggerGenerator) addDefinition(t reflect.Type) {
+	if _, ok := s.definitionTypes[t]; ok {
+		return
+	}
+	s.definitionTypes[t] = struct{}{}
+	s.definitionQueue = append(s.definitionQueue, t)
+}
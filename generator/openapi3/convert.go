@@ -0,0 +1,544 @@
+package openapi3
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+const definitionsPrefix = "#/definitions/"
+
+// Extensions of Swagger 2.0 schemas and parameters converted to OpenAPI 3 keywords
+const (
+	DeprecatedExtension = "x-deprecated"
+	WriteOnlyExtension  = "x-write-only"
+	// DiscriminatorMappingExtension maps discriminator values to schemas references
+	DiscriminatorMappingExtension = "x-discriminator-mapping"
+)
+
+// schemaKeywordExtensions maps extensions to schema keywords
+var schemaKeywordExtensions = map[string]string{
+	DeprecatedExtension: "deprecated",
+	WriteOnlyExtension:  "writeOnly",
+}
+
+const (
+	jsonContentType      = "application/json"
+	multipartContentType = "multipart/form-data"
+	formContentType      = "application/x-www-form-urlencoded"
+	binaryContentType    = "application/octet-stream"
+)
+
+// FromSwagger converts Swagger 2.0 spec to OpenAPI document of given version.
+// version is Version30 or Version31
+func FromSwagger(sw openapi.Swagger, version string) (*Document, error) {
+	if version != Version30 && version != Version31 {
+		return nil, fmt.Errorf("unsupported OpenAPI version: %s", version)
+	}
+	c := converter{version: version}
+	doc := &Document{
+		OpenAPI:      version,
+		Info:         sw.Info,
+		Servers:      servers(sw),
+		Paths:        make(map[string]*PathItem),
+		Security:     sw.Security,
+		Tags:         sw.Tags,
+		ExternalDocs: sw.ExternalDocs,
+		Extensions:   sw.Extensions,
+	}
+	if doc.Info == nil {
+		doc.Info = &openapi.Info{}
+	}
+	if len(sw.Definitions) > 0 {
+		doc.Components.Schemas = make(map[string]openapi.Schema, len(sw.Definitions))
+		for name, def := range sw.Definitions {
+			def := def
+			doc.Components.Schemas[name] = *c.schema(&def)
+		}
+	}
+	if len(sw.SecurityDefinitions) > 0 {
+		doc.Components.SecuritySchemes = make(map[string]*SecurityScheme, len(sw.SecurityDefinitions))
+		for name, secDef := range sw.SecurityDefinitions {
+			doc.Components.SecuritySchemes[name] = securityScheme(secDef)
+		}
+	}
+	if sw.Paths == nil {
+		return doc, nil
+	}
+	for path, pi := range sw.Paths.Paths {
+		doc.Paths[path] = &PathItem{
+			Get:     c.operation(pi.Get, sw.Consumes, sw.Produces),
+			Put:     c.operation(pi.Put, sw.Consumes, sw.Produces),
+			Post:    c.operation(pi.Post, sw.Consumes, sw.Produces),
+			Delete:  c.operation(pi.Delete, sw.Consumes, sw.Produces),
+			Options: c.operation(pi.Options, sw.Consumes, sw.Produces),
+			Head:    c.operation(pi.Head, sw.Consumes, sw.Produces),
+			Patch:   c.operation(pi.Patch, sw.Consumes, sw.Produces),
+		}
+	}
+	return doc, nil
+}
+
+type converter struct {
+	version string
+}
+
+// servers builds server URLs from host, base path and schemes
+func servers(sw openapi.Swagger) []Server {
+	if sw.Host == "" {
+		if sw.BasePath == "" || sw.BasePath == "/" {
+			return nil
+		}
+		return []Server{{URL: sw.BasePath}}
+	}
+	schemes := sw.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	res := make([]Server, 0, len(schemes))
+	for _, scheme := range schemes {
+		res = append(res, Server{URL: scheme + "://" + sw.Host + sw.BasePath})
+	}
+	return res
+}
+
+func securityScheme(secDef *openapi.SecurityScheme) *SecurityScheme {
+	res := &SecurityScheme{
+		Description: secDef.Description,
+	}
+	switch secDef.Type {
+	case "basic":
+		res.Type = "http"
+		res.Scheme = "basic"
+	case "apiKey":
+		res.Type = "apiKey"
+		res.Name = secDef.Name
+		res.In = secDef.In
+	case "oauth2":
+		res.Type = "oauth2"
+		scopes := secDef.Scopes
+		if scopes == nil {
+			scopes = make(map[string]string)
+		}
+		flow := &OAuthFlow{
+			AuthorizationURL: secDef.AuthorizationURL,
+			TokenURL:         secDef.TokenURL,
+			Scopes:           scopes,
+		}
+		res.Flows = &OAuthFlows{}
+		switch secDef.Flow {
+		case "implicit":
+			res.Flows.Implicit = flow
+		case "password":
+			res.Flows.Password = flow
+		case "application":
+			res.Flows.ClientCredentials = flow
+		case "accessCode":
+			res.Flows.AuthorizationCode = flow
+		}
+	default:
+		res.Type = secDef.Type
+	}
+	return res
+}
+
+func (c converter) operation(op *openapi.Operation, consumes, produces []string) *Operation {
+	if op == nil {
+		return nil
+	}
+	if len(op.Consumes) > 0 {
+		consumes = op.Consumes
+	}
+	if len(op.Produces) > 0 {
+		produces = op.Produces
+	}
+	res := &Operation{
+		Tags:        op.Tags,
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.ID,
+		Responses:   make(map[string]*Response),
+		Security:    op.Security,
+		Deprecated:  op.Deprecated,
+	}
+	formParams := make([]openapi.Parameter, 0)
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "body":
+			res.RequestBody = c.bodyRequest(param, consumes)
+		case "formData":
+			formParams = append(formParams, param)
+		default:
+			res.Parameters = append(res.Parameters, c.parameter(param))
+		}
+	}
+	if len(formParams) > 0 {
+		res.RequestBody = c.formRequest(formParams, consumes)
+	}
+	if op.Responses != nil {
+		if op.Responses.Default != nil {
+			res.Responses["default"] = c.response(op.Responses.Default, "Default response", produces)
+		}
+		for status, resp := range op.Responses.StatusCodeResponses {
+			resp := resp
+			res.Responses[strconv.Itoa(status)] = c.response(&resp, http.StatusText(status), produces)
+		}
+	}
+	return res
+}
+
+func (c converter) parameter(param openapi.Parameter) Parameter {
+	res := Parameter{
+		Name:        param.Name,
+		In:          param.In,
+		Description: param.Description,
+		Required:    param.Required,
+		Schema:      c.paramSchema(param.SimpleSchema, param.CommonValidations),
+		Example:     param.Example,
+	}
+	res.Deprecated, _ = param.Extensions.GetBool(DeprecatedExtension)
+	if param.Type == "array" {
+		res.Style, res.Explode = paramStyle(param.In, param.CollectionFormat)
+	}
+	return res
+}
+
+// paramStyle maps Swagger 2.0 array collection format to serialization style
+func paramStyle(in, collectionFormat string) (string, *bool) {
+	explode := false
+	switch collectionFormat {
+	case "multi":
+		explode = true
+		return "form", &explode
+	case "ssv":
+		return "spaceDelimited", &explode
+	case "pipes":
+		return "pipeDelimited", &explode
+	}
+	if in == "query" || in == "cookie" {
+		return "form", &explode
+	}
+	return "simple", &explode
+}
+
+func (c converter) paramSchema(simple openapi.SimpleSchema, validations openapi.CommonValidations) *openapi.Schema {
+	s := &openapi.Schema{}
+	if simple.Type != "" {
+		s.Type = openapi.StringOrArray{simple.Type}
+	}
+	s.Format = simple.Format
+	s.Default = simple.Default
+	s.Example = simple.Example
+	s.Nullable = simple.Nullable
+	s.Maximum = validations.Maximum
+	s.ExclusiveMaximum = validations.ExclusiveMaximum
+	s.Minimum = validations.Minimum
+	s.ExclusiveMinimum = validations.ExclusiveMinimum
+	s.MaxLength = validations.MaxLength
+	s.MinLength = validations.MinLength
+	s.Pattern = validations.Pattern
+	s.MaxItems = validations.MaxItems
+	s.MinItems = validations.MinItems
+	s.UniqueItems = validations.UniqueItems
+	s.MultipleOf = validations.MultipleOf
+	s.Enum = validations.Enum
+	if simple.Items != nil {
+		s.Items = &openapi.SchemaOrArray{
+			Schema: c.paramSchema(simple.Items.SimpleSchema, simple.Items.CommonValidations),
+		}
+	}
+	if simple.Type == "file" {
+		s.Type = openapi.StringOrArray{"string"}
+		s.Format = "binary"
+	}
+	return c.schema(s)
+}
+
+func (c converter) bodyRequest(param openapi.Parameter, consumes []string) *RequestBody {
+	contentTypes := make([]string, 0, len(consumes))
+	for _, contentType := range consumes {
+		if contentType != multipartContentType && contentType != formContentType {
+			contentTypes = append(contentTypes, contentType)
+		}
+	}
+	if len(contentTypes) == 0 {
+		contentTypes = []string{jsonContentType}
+	}
+	res := &RequestBody{
+		Description: param.Description,
+		Required:    param.Required,
+		Content:     make(map[string]MediaType, len(contentTypes)),
+	}
+	for _, contentType := range contentTypes {
+		res.Content[contentType] = MediaType{
+			Schema: c.schema(param.Schema),
+		}
+	}
+	return res
+}
+
+// formRequest joins form parameters to object schema. Files become binary strings,
+// object parts like JSON request part are encoded according to their content type
+func (c converter) formRequest(params []openapi.Parameter, consumes []string) *RequestBody {
+	contentType := multipartContentType
+	hasFiles := false
+	for _, param := range params {
+		if isFileParam(param) {
+			hasFiles = true
+		}
+	}
+	if !hasFiles {
+		for _, consumed := range consumes {
+			if consumed == formContentType {
+				contentType = formContentType
+			}
+		}
+	}
+	formSchema := &openapi.Schema{}
+	formSchema.Type = openapi.StringOrArray{"object"}
+	formSchema.Properties = make(openapi.SchemaProperties, len(params))
+	encoding := make(map[string]Encoding)
+	required := false
+	for _, param := range params {
+		pSchema := c.paramSchema(param.SimpleSchema, param.CommonValidations)
+		pSchema.Description = param.Description
+		switch {
+		case isFileParam(param):
+			encoding[param.Name] = Encoding{ContentType: binaryContentType}
+		case param.Type == "" && param.Default != nil:
+			// Request struct part sent as JSON document
+			pSchema.Type = openapi.StringOrArray{"object"}
+			encoding[param.Name] = Encoding{ContentType: jsonContentType}
+		}
+		formSchema.Properties[param.Name] = *pSchema
+		if param.Required {
+			formSchema.Required = append(formSchema.Required, param.Name)
+			required = true
+		}
+	}
+	sort.Strings(formSchema.Required)
+	media := MediaType{
+		Schema: formSchema,
+	}
+	if contentType == multipartContentType && len(encoding) > 0 {
+		media.Encoding = encoding
+	}
+	return &RequestBody{
+		Required: required,
+		Content: map[string]MediaType{
+			contentType: media,
+		},
+	}
+}
+
+// isFileParam reports if form parameter is file or array of files
+func isFileParam(param openapi.Parameter) bool {
+	if param.Type == "file" {
+		return true
+	}
+	return param.Items != nil && (param.Items.Type == "file" || param.Items.Format == "binary")
+}
+
+func (c converter) response(resp *openapi.Response, defaultDescription string, produces []string) *Response {
+	res := &Response{
+		Description: resp.Description,
+	}
+	if res.Description == "" {
+		res.Description = defaultDescription
+	}
+	if resp.Schema != nil {
+		contentTypes := produces
+		if len(contentTypes) == 0 {
+			contentTypes = []string{jsonContentType}
+		}
+		res.Content = make(map[string]MediaType, len(contentTypes))
+		for _, contentType := range contentTypes {
+			res.Content[contentType] = MediaType{
+				Schema:  c.schema(resp.Schema),
+				Example: resp.Examples[contentType],
+			}
+		}
+	}
+	if len(resp.Headers) > 0 {
+		res.Headers = make(map[string]Header, len(resp.Headers))
+		for name, header := range resp.Headers {
+			res.Headers[name] = Header{
+				Description: header.Description,
+				Schema:      c.paramSchema(header.SimpleSchema, header.CommonValidations),
+			}
+		}
+	}
+	return res
+}
+
+// schema returns copy of Swagger 2.0 schema converted to OpenAPI 3 schema: references point to components,
+// nullability and exclusive limits are rendered in form of target version
+func (c converter) schema(s *openapi.Schema) *openapi.Schema {
+	if s == nil {
+		return nil
+	}
+	res := *s
+	if ref := s.Ref.String(); strings.HasPrefix(ref, definitionsPrefix) {
+		res.Ref = openapi.MustCreateRef(schemasPrefix + strings.TrimPrefix(ref, definitionsPrefix))
+	}
+	if s.Properties != nil {
+		res.Properties = make(openapi.SchemaProperties, len(s.Properties))
+		for name, prop := range s.Properties {
+			prop := prop
+			res.Properties[name] = *c.schema(&prop)
+		}
+	}
+	if s.PatternProperties != nil {
+		res.PatternProperties = make(openapi.SchemaProperties, len(s.PatternProperties))
+		for name, prop := range s.PatternProperties {
+			prop := prop
+			res.PatternProperties[name] = *c.schema(&prop)
+		}
+	}
+	if s.Items != nil {
+		res.Items = &openapi.SchemaOrArray{
+			Schema: c.schema(s.Items.Schema),
+		}
+		if s.Items.Schemas != nil {
+			res.Items.Schemas = c.schemas(s.Items.Schemas)
+		}
+	}
+	if s.AdditionalProperties != nil {
+		res.AdditionalProperties = &openapi.SchemaOrBool{
+			Allows: s.AdditionalProperties.Allows,
+			Schema: c.schema(s.AdditionalProperties.Schema),
+		}
+	}
+	res.AllOf = c.schemas(s.AllOf)
+	res.OneOf = c.schemas(s.OneOf)
+	res.AnyOf = c.schemas(s.AnyOf)
+	res.Not = c.schema(s.Not)
+	res.ExtraProps = copyProps(s.ExtraProps)
+	res.Extensions = copyProps(s.Extensions)
+	if s.Discriminator != "" {
+		res.Discriminator = ""
+		discriminator := map[string]interface{}{
+			"propertyName": s.Discriminator,
+		}
+		if mapping := discriminatorMapping(res.Extensions); len(mapping) > 0 {
+			discriminator["mapping"] = mapping
+		}
+		delete(res.Extensions, DiscriminatorMappingExtension)
+		res.ExtraProps = setProp(res.ExtraProps, "discriminator", discriminator)
+	}
+	for extension, keyword := range schemaKeywordExtensions {
+		if val, ok := res.Extensions.GetBool(extension); ok {
+			delete(res.Extensions, extension)
+			res.ExtraProps = setProp(res.ExtraProps, keyword, val)
+		}
+	}
+	nullable := s.Nullable
+	if xNullable, ok := res.Extensions.GetBool("x-nullable"); ok {
+		nullable = nullable || xNullable
+		delete(res.Extensions, "x-nullable")
+	}
+	if c.version == Version31 {
+		return c.schema31(&res, nullable)
+	}
+	res.Nullable = nullable
+	return &res
+}
+
+// schema31 renders nullability as null type and exclusive limits as numbers, like JSON Schema 2020-12
+func (c converter) schema31(s *openapi.Schema, nullable bool) *openapi.Schema {
+	s.Nullable = false
+	if s.ExclusiveMinimum && s.Minimum != nil {
+		s.ExtraProps = setProp(s.ExtraProps, "exclusiveMinimum", *s.Minimum)
+		s.ExclusiveMinimum = false
+		s.Minimum = nil
+	}
+	if s.ExclusiveMaximum && s.Maximum != nil {
+		s.ExtraProps = setProp(s.ExtraProps, "exclusiveMaximum", *s.Maximum)
+		s.ExclusiveMaximum = false
+		s.Maximum = nil
+	}
+	if !nullable {
+		return s
+	}
+	if s.Ref.String() != "" {
+		nullSchema := openapi.Schema{}
+		nullSchema.Type = openapi.StringOrArray{"null"}
+		refSchema := openapi.Schema{}
+		refSchema.Ref = s.Ref
+		s.Ref = openapi.Ref{}
+		s.AnyOf = append([]openapi.Schema{refSchema, nullSchema}, s.AnyOf...)
+		return s
+	}
+	if len(s.Type) == 0 && len(s.AllOf) > 0 {
+		// Composed schema, like reference wrapped to add annotations
+		nullSchema := openapi.Schema{}
+		nullSchema.Type = openapi.StringOrArray{"null"}
+		allOfSchema := openapi.Schema{}
+		allOfSchema.AllOf = s.AllOf
+		s.AllOf = nil
+		s.AnyOf = append([]openapi.Schema{allOfSchema, nullSchema}, s.AnyOf...)
+		return s
+	}
+	if len(s.Type) > 0 && !s.Type.Contains("null") {
+		s.Type = append(s.Type, "null")
+	}
+	return s
+}
+
+func (c converter) schemas(schemas []openapi.Schema) []openapi.Schema {
+	if schemas == nil {
+		return nil
+	}
+	res := make([]openapi.Schema, 0, len(schemas))
+	for _, s := range schemas {
+		s := s
+		res = append(res, *c.schema(&s))
+	}
+	return res
+}
+
+func copyProps(props map[string]interface{}) map[string]interface{} {
+	if props == nil {
+		return nil
+	}
+	res := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		res[k] = v
+	}
+	return res
+}
+
+func setProp(props map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	if props == nil {
+		props = make(map[string]interface{})
+	}
+	props[key] = value
+	return props
+}
+
+// discriminatorMapping returns discriminator mapping extension with references to components schemas
+func discriminatorMapping(extensions openapi.Extensions) map[string]string {
+	mapping := make(map[string]string)
+	switch ext := extensions[DiscriminatorMappingExtension].(type) {
+	case map[string]string:
+		for value, ref := range ext {
+			mapping[value] = ref
+		}
+	case map[string]interface{}:
+		for value, ref := range ext {
+			if ref, ok := ref.(string); ok {
+				mapping[value] = ref
+			}
+		}
+	}
+	for value, ref := range mapping {
+		if strings.HasPrefix(ref, definitionsPrefix) {
+			mapping[value] = schemasPrefix + strings.TrimPrefix(ref, definitionsPrefix)
+		}
+	}
+	return mapping
+}
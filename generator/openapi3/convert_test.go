@@ -0,0 +1,130 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"testing"
+
+	openapi "github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSwagger() openapi.Swagger {
+	sw := openapi.Swagger{}
+	sw.Swagger = "2.0"
+	sw.Info = &openapi.Info{}
+	sw.Host = "api.example.com"
+	sw.BasePath = "/v1"
+	sw.Schemes = []string{"https", "http"}
+	minAge := 0.0
+	age := openapi.Int64Property()
+	age.Minimum = &minAge
+	age.ExclusiveMinimum = true
+	nickname := openapi.StringProperty()
+	nickname.Nullable = true
+	manager := openapi.RefProperty("#/definitions/User")
+	manager.AddExtension("x-nullable", true)
+	reviewer := openapi.Schema{}
+	reviewer.AllOf = []openapi.Schema{*openapi.RefProperty("#/definitions/User")}
+	reviewer.AddExtension("x-nullable", true)
+	sw.Definitions = openapi.Definitions{
+		"User": *openapi.RefProperty("#/definitions/Profile"),
+		"Profile": openapi.Schema{
+			SchemaProps: openapi.SchemaProps{
+				Type: openapi.StringOrArray{"object"},
+				Properties: openapi.SchemaProperties{
+					"age":      *age,
+					"nickname": *nickname,
+					"manager":  *manager,
+					"reviewer": reviewer,
+				},
+			},
+		},
+	}
+	return sw
+}
+
+func TestFromSwaggerServers(t *testing.T) {
+	doc, err := FromSwagger(testSwagger(), Version30)
+	assert.NoError(t, err)
+	assert.Equal(t, []Server{{URL: "https://api.example.com/v1"}, {URL: "http://api.example.com/v1"}}, doc.Servers)
+
+	_, err = FromSwagger(testSwagger(), "2.0")
+	assert.Error(t, err)
+}
+
+func TestFromSwagger30Schemas(t *testing.T) {
+	doc, err := FromSwagger(testSwagger(), Version30)
+	assert.NoError(t, err)
+	user := doc.Components.Schemas["User"]
+	assert.Equal(t, "#/components/schemas/Profile", user.Ref.String())
+	profile := doc.Components.Schemas["Profile"]
+	assert.True(t, profile.Properties["nickname"].Nullable)
+	manager := profile.Properties["manager"]
+	assert.True(t, manager.Nullable)
+	assert.Equal(t, "#/components/schemas/User", manager.Ref.String())
+	assert.NotContains(t, manager.Extensions, "x-nullable")
+	reviewer := profile.Properties["reviewer"]
+	assert.True(t, reviewer.Nullable)
+	if assert.Equal(t, 1, len(reviewer.AllOf)) {
+		assert.Equal(t, "#/components/schemas/User", reviewer.AllOf[0].Ref.String())
+	}
+	assert.True(t, profile.Properties["age"].ExclusiveMinimum)
+}
+
+func TestFromSwagger31Schemas(t *testing.T) {
+	doc, err := FromSwagger(testSwagger(), Version31)
+	assert.NoError(t, err)
+	profile := doc.Components.Schemas["Profile"]
+	nickname := profile.Properties["nickname"]
+	assert.False(t, nickname.Nullable)
+	assert.Equal(t, openapi.StringOrArray{"string", "null"}, nickname.Type)
+
+	manager := profile.Properties["manager"]
+	assert.Equal(t, "", manager.Ref.String())
+	if assert.Equal(t, 2, len(manager.AnyOf)) {
+		assert.Equal(t, "#/components/schemas/User", manager.AnyOf[0].Ref.String())
+		assert.Equal(t, openapi.StringOrArray{"null"}, manager.AnyOf[1].Type)
+	}
+	reviewerJSON, err := json.Marshal(profile.Properties["reviewer"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"anyOf": [{"allOf": [{"$ref": "#/components/schemas/User"}]}, {"type": "null"}]}`, string(reviewerJSON))
+
+	ageJSON, err := json.Marshal(profile.Properties["age"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "integer", "format": "int64", "exclusiveMinimum": 0}`, string(ageJSON))
+}
+
+func TestFromSwaggerDiscriminator(t *testing.T) {
+	sw := testSwagger()
+	pet := openapi.Schema{}
+	pet.Type = openapi.StringOrArray{"object"}
+	pet.Discriminator = "kind"
+	sw.Definitions["Pet"] = pet
+	doc, err := FromSwagger(sw, Version30)
+	assert.NoError(t, err)
+	petJSON, err := json.Marshal(doc.Components.Schemas["Pet"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "object", "discriminator": {"propertyName": "kind"}}`, string(petJSON))
+
+	pet.AddExtension(DiscriminatorMappingExtension, map[string]string{"cat": "#/definitions/Cat"})
+	sw.Definitions["Pet"] = pet
+	doc, err = FromSwagger(sw, Version31)
+	assert.NoError(t, err)
+	petJSON, err = json.Marshal(doc.Components.Schemas["Pet"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "object", "discriminator": {"propertyName": "kind", "mapping": {"cat": "#/components/schemas/Cat"}}}`, string(petJSON))
+}
+
+func TestFromSwaggerExtensions(t *testing.T) {
+	sw := testSwagger()
+	sw.AddExtension("x-error-codes", []string{"not_found"})
+	doc, err := FromSwagger(sw, Version30)
+	assert.NoError(t, err)
+	docJSON, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	res := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(docJSON, &res))
+	assert.Equal(t, "3.0.3", res["openapi"])
+	assert.Equal(t, []interface{}{"not_found"}, res["x-error-codes"])
+	assert.NotContains(t, res, "swagger")
+}
@@ -0,0 +1,142 @@
+// Package openapi3 contains OpenAPI 3.0 and 3.1 document model and conversion from Swagger 2.0 spec.
+// Schemas reuse go-openapi Schema, which is compatible with OpenAPI 3 schema object
+package openapi3
+
+import (
+	"encoding/json"
+
+	openapi "github.com/go-openapi/spec"
+	"github.com/go-openapi/swag"
+)
+
+const (
+	Version30 = "3.0.3"
+	Version31 = "3.1.0"
+)
+
+const schemasPrefix = "#/components/schemas/"
+
+type Document struct {
+	OpenAPI      string                         `json:"openapi"`
+	Info         *openapi.Info                  `json:"info"`
+	Servers      []Server                       `json:"servers,omitempty"`
+	Paths        map[string]*PathItem           `json:"paths"`
+	Components   Components                     `json:"components"`
+	Security     []map[string][]string          `json:"security,omitempty"`
+	Tags         []openapi.Tag                  `json:"tags,omitempty"`
+	ExternalDocs *openapi.ExternalDocumentation `json:"externalDocs,omitempty"`
+	// Extensions are top level x- properties
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON adds extensions to document properties
+func (d Document) MarshalJSON() ([]byte, error) {
+	type document Document
+	b1, err := json.Marshal(document(d))
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Extensions) == 0 {
+		return b1, nil
+	}
+	b2, err := json.Marshal(d.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	return swag.ConcatJSON(b1, b2), nil
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description,omitempty"`
+	Required    bool            `json:"required,omitempty"`
+	Deprecated  bool            `json:"deprecated,omitempty"`
+	Style       string          `json:"style,omitempty"`
+	Explode     *bool           `json:"explode,omitempty"`
+	Schema      *openapi.Schema `json:"schema,omitempty"`
+	Example     interface{}     `json:"example,omitempty"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema   *openapi.Schema     `json:"schema,omitempty"`
+	Example  interface{}         `json:"example,omitempty"`
+	Encoding map[string]Encoding `json:"encoding,omitempty"`
+}
+
+type Encoding struct {
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string          `json:"description,omitempty"`
+	Schema      *openapi.Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]openapi.Schema  `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string      `json:"type"`
+	Description  string      `json:"description,omitempty"`
+	Name         string      `json:"name,omitempty"`
+	In           string      `json:"in,omitempty"`
+	Scheme       string      `json:"scheme,omitempty"`
+	BearerFormat string      `json:"bearerFormat,omitempty"`
+	Flows        *OAuthFlows `json:"flows,omitempty"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"reflect"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+// MergePatchContentType is content type of JSON Merge Patch (RFC 7396) bodies, accepted by PATCH operations
+// in addition to application/json
+const MergePatchContentType = "application/merge-patch+json"
+
+// OptionalValue is implemented by field types which value can be absent in request, like wrapper.Optional[T].
+// Fields are documented by schema of element type and are not required. Nullable fields also accept null
+type OptionalValue interface {
+	OptionalType() (elem reflect.Type, nullable bool)
+}
+
+var optionalValueIface = reflect.TypeOf((*OptionalValue)(nil)).Elem()
+
+// OptionalElem returns element type of optional field type and reports if field accepts null
+func OptionalElem(t reflect.Type) (elem reflect.Type, nullable bool, ok bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface || !reflect.PtrTo(t).Implements(optionalValueIface) {
+		return nil, false, false
+	}
+	elem, nullable = reflect.New(t).Interface().(OptionalValue).OptionalType()
+	return elem, nullable, true
+}
+
+func isOptional(t reflect.Type) bool {
+	_, _, ok := OptionalElem(t)
+	return ok
+}
+
+// nullableSchema returns copy of schema marked by x-nullable extension. Schemas of simple types are shared,
+// so extensions are copied too. Siblings of $ref are ignored, so reference is wrapped by allOf
+func nullableSchema(schema *openapi.Schema) *openapi.Schema {
+	if schema.Ref.String() != "" {
+		return &openapi.Schema{
+			SchemaProps:      openapi.SchemaProps{AllOf: []openapi.Schema{*schema}},
+			VendorExtensible: openapi.VendorExtensible{Extensions: openapi.Extensions{"x-nullable": true}},
+		}
+	}
+	res := *schema
+	res.Extensions = make(openapi.Extensions, len(schema.Extensions)+1)
+	for key, val := range schema.Extensions {
+		res.Extensions[key] = val
+	}
+	res.Extensions.Add("x-nullable", true)
+	return &res
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"encoding"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	durationType         = reflect.TypeOf(time.Duration(0))
+	uuidType             = reflect.TypeOf(uuid.UUID{})
+	textUnmarshalerIface = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// GetParamType returns swagger type and format for request field bound from path or query string.
+// ok is false when field of this type cannot be bound from string value. Unsigned integers are documented
+// like unsignedSchema, unsignedParam sets their minimum.
+func GetParamType(paramType reflect.Type) (typeName string, format string, ok bool) {
+	if paramType.Kind() == reflect.Ptr {
+		paramType = paramType.Elem()
+	}
+	switch paramType {
+	case timeType:
+		return "string", "date-time", true
+	case uuidType:
+		return "string", "uuid", true
+	case durationType:
+		return "string", "duration", true
+	}
+	if reflect.PtrTo(paramType).Implements(textUnmarshalerIface) {
+		return "string", "", true
+	}
+	switch paramType.Kind() {
+	case reflect.String:
+		return "string", "", true
+	case reflect.Bool:
+		return "boolean", "", true
+	case reflect.Int8:
+		return "integer", "int8", true
+	case reflect.Int16:
+		return "integer", "int16", true
+	case reflect.Int32:
+		return "integer", "int32", true
+	case reflect.Int, reflect.Int64:
+		return "integer", "int64", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", unsignedFormat(paramType.Kind()), true
+	case reflect.Float32:
+		return "number", "float", true
+	case reflect.Float64:
+		return "number", "double", true
+	}
+	return "", "", false
+}
+
+// unsignedParam reports if parameter of type is unsigned integer, documented with minimum 0.
+// Registered and self-described types are documented by their schemas
+func (r *TypeRegistry) unsignedParam(t reflect.Type) bool {
+	if elem, _, optional := OptionalElem(t); optional {
+		return r.unsignedParam(elem)
+	}
+	if _, registered := r.Schema(t); registered || r.hasProvider(t) {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerIface) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// ParamKind describes how request field is bound from path, query, header or cookie values
+type ParamKind int
+
+const (
+	// UnsupportedParam fields cannot be bound from request parameters
+	UnsupportedParam ParamKind = iota
+	// SimpleParam fields are bound from single value
+	SimpleParam
+	// ArrayParam fields are slices bound from repeated or delimited values
+	ArrayParam
+	// ObjectParam fields are structs or maps bound from bracketed query keys, like filter[name]=x
+	ObjectParam
+)
+
+// Collection formats for array parameters, set as third part of param tag: `param:"ids,query,pipes"`
+const (
+	CollectionCSV   = "csv"
+	CollectionSSV   = "ssv"
+	CollectionTSV   = "tsv"
+	CollectionPipes = "pipes"
+	CollectionMulti = "multi"
+)
+
+// GetParamKind returns how field of paramType can be bound from request parameters
+func GetParamKind(paramType reflect.Type) ParamKind {
+	var types *TypeRegistry
+	return types.ParamKind(paramType)
+}
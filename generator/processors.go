@@ -12,25 +12,50 @@ import (
 )
 
 // pathParamsProcessor parse path, search for path parameters. Create path for swagger annotation
-func (s *SwaggerGenerator) pathParamsProcessor(op *openapi.Operation, path string) string {
+func (s *SwaggerGenerator) pathParamsProcessor(op *openapi.Operation, path string, reqType *reflect.Type) string {
 	pParams := regexp.MustCompile(`:\w+`).FindAllString(path, -1)
 	for _, param := range pParams {
 		pName := param[1:]
 		pathPlace := "{" + pName + "}"
 		echoPathPlace := ":" + pName
 		path = strings.ReplaceAll(path, echoPathPlace, pathPlace)
-		sParam := openapi.Parameter{}
-		sParam.Name = pName
-		sParam.In = "path"
-		sParam.Type = "string"
+		sParam := s.pathParam(reqType, pName)
 		op.Parameters = append(op.Parameters, sParam)
 	}
 	return path
 }
 
+// pathParam creates path parameter with type of request struct field with the same name, string by default
+func (s *SwaggerGenerator) pathParam(reqType *reflect.Type, pName string) openapi.Parameter {
+	sParam := openapi.Parameter{}
+	sParam.Name = pName
+	sParam.In = "path"
+	sParam.Type = "string"
+	sParam.Required = true
+	if reqType == nil {
+		return sParam
+	}
+	for _, field := range JSONFields(*reqType) {
+		fInfo := GetFieldInfo(field)
+		if fInfo == nil || fInfo.Name != pName {
+			continue
+		}
+		if s.types.ParamKind(field.Type) != SimpleParam {
+			continue
+		}
+		sParam.Type, sParam.Format, _ = s.types.ParamType(field.Type)
+		if s.types.unsignedParam(field.Type) {
+			sParam.Minimum = float64Ptr(0)
+		}
+		sParam.Enum = EnumValues(field.Type, s.docs)
+		applyParamRules(&sParam, field)
+		break
+	}
+	return sParam
+}
+
 func (s *SwaggerGenerator) queryParamsProcessor(op *openapi.Operation, paramType reflect.Type, skipParams map[string]struct{}) {
-	for i := 0; i < paramType.NumField(); i++ {
-		field := paramType.Field(i)
+	for _, field := range JSONFields(paramType) {
 		fInfo := GetFieldInfo(field)
 		if fInfo == nil {
 			continue
@@ -42,38 +67,114 @@ func (s *SwaggerGenerator) queryParamsProcessor(op *openapi.Operation, paramType
 		if skip {
 			continue
 		}
-		if field.Type.Kind() != reflect.String {
-			// Only type string supported for query parameters
-			continue
-		}
-		sParam := openapi.Parameter{}
-		sParam.Name = fInfo.Name
-		sParam.In = "query"
-		sParam.Type = "string"
-		op.Parameters = append(op.Parameters, sParam)
+		op.Parameters = append(op.Parameters, s.fieldParams(field, fInfo, paramLocation(fInfo))...)
 	}
 }
 
-func (s *SwaggerGenerator) queryParamsOnlyProcessor(op *openapi.Operation, paramType reflect.Type) {
-	for i := 0; i < paramType.NumField(); i++ {
-		field := paramType.Field(i)
+// queryParamsOnlyProcessor process request fields explicitly marked as query, header or cookie parameters.
+// Other fields are passed in request body. Returns names of processed fields
+func (s *SwaggerGenerator) queryParamsOnlyProcessor(op *openapi.Operation, paramType reflect.Type) []string {
+	processed := make([]string, 0)
+	for _, field := range JSONFields(paramType) {
 		fInfo := GetFieldInfo(field)
 		if fInfo == nil {
 			continue
 		}
-		if fInfo.In != "query" {
+		if fInfo.In != "query" && fInfo.In != "header" && fInfo.In != "cookie" {
 			continue
 		}
-		if field.Type.Kind() != reflect.String {
-			// Only type string supported for query parameters
+		fParams := s.fieldParams(field, fInfo, fInfo.In)
+		if len(fParams) == 0 {
 			continue
 		}
-		sParam := openapi.Parameter{}
-		sParam.Name = fInfo.Name
-		sParam.In = "query"
-		sParam.Type = "string"
-		op.Parameters = append(op.Parameters, sParam)
+		op.Parameters = append(op.Parameters, fParams...)
+		processed = append(processed, fInfo.Name)
+	}
+	return processed
+}
+
+// paramLocation returns location of non path parameter. Fields without explicit location are query parameters
+func paramLocation(fInfo *fieldInfo) string {
+	if fInfo.In == "header" || fInfo.In == "cookie" {
+		return fInfo.In
+	}
+	return "query"
+}
+
+// fieldParams creates operation parameters for request field. Only query parameters can be objects,
+// they are expanded to parameter per field: filter[name], filter[age]
+func (s *SwaggerGenerator) fieldParams(field reflect.StructField, fInfo *fieldInfo, in string) []openapi.Parameter {
+	switch s.types.ParamKind(field.Type) {
+	case SimpleParam, ArrayParam:
+		sParam := s.simpleParam(field.Type, fInfo.Name, in, fInfo.CollectionFormat)
+		applyParamRules(&sParam, field)
+		return []openapi.Parameter{sParam}
+	case ObjectParam:
+		if in != "query" {
+			return nil
+		}
+		return s.objectParams(field.Type, fInfo.Name)
+	}
+	return nil
+}
+
+// applyParamRules sets constraints from field validate tag and documentation from field doc tags to parameter
+func applyParamRules(param *openapi.Parameter, field reflect.StructField) {
+	parseValidateTag(field.Tag.Get("validate"), param.Type).applyToParam(param)
+	parseDocTags(field).applyToParam(param)
+}
+
+func (s *SwaggerGenerator) simpleParam(paramType reflect.Type, name string, in string, collectionFormat string) openapi.Parameter {
+	sParam := openapi.Parameter{}
+	sParam.Name = name
+	sParam.In = in
+	if s.types.ParamKind(paramType) != ArrayParam {
+		sParam.Type, sParam.Format, _ = s.types.ParamType(paramType)
+		if s.types.unsignedParam(paramType) {
+			sParam.Minimum = float64Ptr(0)
+		}
+		sParam.Enum = EnumValues(paramType, s.docs)
+		return sParam
+	}
+	itemType, itemFormat, _ := s.types.ParamType(paramType.Elem())
+	sParam.Type = "array"
+	sParam.Items = &openapi.Items{}
+	sParam.Items.Typed(itemType, itemFormat)
+	if s.types.unsignedParam(paramType.Elem()) {
+		sParam.Items.Minimum = float64Ptr(0)
+	}
+	sParam.Items.Enum = EnumValues(paramType.Elem(), s.docs)
+	if collectionFormat == CollectionMulti && in != "query" {
+		// multi is allowed only for query and form parameters
+		collectionFormat = CollectionCSV
+	}
+	sParam.CollectionFormat = collectionFormat
+	return sParam
+}
+
+func (s *SwaggerGenerator) objectParams(paramType reflect.Type, name string) []openapi.Parameter {
+	params := make([]openapi.Parameter, 0)
+	if paramType.Kind() == reflect.Map {
+		sParam := s.simpleParam(paramType.Elem(), name+"[key]", "query", "")
+		sParam.Description = fmt.Sprintf("Values passed as %s[key]=value", name)
+		return append(params, sParam)
+	}
+	for _, field := range JSONFields(paramType) {
+		fInfo := GetFieldInfo(field)
+		if fInfo == nil {
+			continue
+		}
+		subName := name + "[" + fInfo.Name + "]"
+		switch s.types.ParamKind(field.Type) {
+		case SimpleParam, ArrayParam:
+			sParam := s.simpleParam(field.Type, subName, "query", fInfo.CollectionFormat)
+			applyParamRules(&sParam, field)
+			params = append(params, sParam)
+		case ObjectParam:
+			params = append(params, s.objectParams(field.Type, subName)...)
+		}
 	}
+	return params
 }
 
 func (s *SwaggerGenerator) bodyParamsProcessor(op *openapi.Operation, routeInfo RouteInfo) {
@@ -84,15 +185,7 @@ func (s *SwaggerGenerator) bodyParamsProcessor(op *openapi.Operation, routeInfo
 	handlerInfo := routeInfo.Handler
 	handlerInfo.FileUpload = append(handlerInfo.FileUpload, routeInfo.Parameters.FileUpload...)
 	paramType := *routeInfo.Handler.RequestType
-	pName := paramType.Name()
-	s.definitionTypes[pName] = paramType
-	for i := 0; i < paramType.NumField(); i++ {
-		field := paramType.Field(i)
-		if field.Type.Kind() == reflect.Struct {
-			s.definitionTypes[field.Type.Name()] = field.Type
-		}
-	}
-	opParam := s.generateSchemaBodyParam(pName, paramType)
+	opParam := s.generateSchemaBodyParam(paramType.Name(), paramType)
 	uploadParam := s.processFileUploadParam(paramType)
 	for _, uParam := range uploadParam {
 		var found bool
@@ -110,7 +203,7 @@ func (s *SwaggerGenerator) bodyParamsProcessor(op *openapi.Operation, routeInfo
 
 	if len(handlerInfo.FileUpload) > 0 {
 		defaultVal := reflect.New(paramType).Elem().Interface()
-		structSkipFields, ok := s.defSkipFields[paramType.Name()]
+		structSkipFields, ok := s.defSkipFields[paramType]
 		if !ok {
 			structSkipFields = make([]string, 0)
 		}
@@ -146,15 +239,16 @@ func (s *SwaggerGenerator) bodyParamsProcessor(op *openapi.Operation, routeInfo
 		op.Parameters = append(op.Parameters, *dataParam)
 	} else {
 		op.Parameters = operationParams
+		if routeInfo.Method == http.MethodPatch {
+			op.Consumes = []string{"application/json", MergePatchContentType}
+		}
 	}
 }
 
 func (s *SwaggerGenerator) processFileUploadParam(paramType reflect.Type) []FileUploadParameters {
 	fParams := make([]FileUploadParameters, 0)
 	fHeaderType := reflect.TypeOf(&multipart.FileHeader{})
-	s.definitionTypes[paramType.Name()] = paramType
-	for i := 0; i < paramType.NumField(); i++ {
-		field := paramType.Field(i)
+	for _, field := range JSONFields(paramType) {
 		if field.Type == fHeaderType {
 			fInfo := GetFieldInfo(field)
 			if fInfo == nil {
@@ -186,47 +280,71 @@ func (s *SwaggerGenerator) processFileUploadParam(paramType reflect.Type) []File
 	return fParams
 }
 
-func (s *SwaggerGenerator) responseProcessor(op *openapi.Operation, respType reflect.Type) {
-	if respType == nil {
-		return
-	}
+// responseProcessor documents successful response with route success status. By default 200 for handlers
+// with result and 204 for handlers returning only error
+func (s *SwaggerGenerator) responseProcessor(op *openapi.Operation, routeInfo RouteInfo) {
 	op.Responses = &openapi.Responses{}
 	op.Responses.StatusCodeResponses = make(map[int]openapi.Response)
-	defName := getDefinitionName(respType)
-	ref := openapi.ResponseRef(
-		fmt.Sprintf("#/definitions/%s", defName),
-	)
-	respSchema := &openapi.Schema{
-		SchemaProps: openapi.SchemaProps{
-			Ref: ref.Ref,
-		},
-		ExtraProps: nil,
+	respType := routeInfo.Handler.OutputType
+	status := routeInfo.Parameters.SuccessStatus
+	if status == 0 {
+		status = http.StatusOK
+		if respType == nil {
+			status = http.StatusNoContent
+		}
+	}
+	resp := openapi.NewResponse().WithDescription(http.StatusText(status))
+	if respType != nil && status != http.StatusNoContent && status != http.StatusNotModified {
+		resp = resp.WithSchema(s.responseBodySchema(*respType))
 	}
-	resp := &openapi.Response{}
-	resp = resp.WithSchema(respSchema)
-	op.Responses.StatusCodeResponses[http.StatusOK] = *resp
+	op.Responses.StatusCodeResponses[status] = *resp
+}
 
-	s.definitionTypes[respType.Name()] = respType
-	for i := 0; i < respType.NumField(); i++ {
-		field := respType.Field(i)
-		if field.Type.Kind() == reflect.Struct {
-			if field.Type == timeType {
-				continue
-			}
-			s.definitionTypes[field.Type.Name()] = field.Type
+// declaredResponsesProcessor adds responses declared in HandlerParameters. Struct bodies are referenced definitions
+func (s *SwaggerGenerator) declaredResponsesProcessor(op *openapi.Operation, responses []ResponseParameters) {
+	if len(responses) == 0 {
+		return
+	}
+	if op.Responses == nil {
+		op.Responses = &openapi.Responses{}
+	}
+	if op.Responses.StatusCodeResponses == nil {
+		op.Responses.StatusCodeResponses = make(map[int]openapi.Response)
+	}
+	for _, respParams := range responses {
+		description := respParams.Description
+		if description == "" {
+			description = http.StatusText(respParams.Status)
+		}
+		resp := openapi.NewResponse().WithDescription(description)
+		if respParams.Body != nil {
+			resp = resp.WithSchema(s.responseBodySchema(reflect.TypeOf(respParams.Body)))
+		}
+		if respParams.Status == 0 {
+			op.Responses.Default = resp
+			continue
 		}
+		op.Responses.StatusCodeResponses[respParams.Status] = *resp
 	}
 }
 
+func (s *SwaggerGenerator) responseBodySchema(bodyType reflect.Type) *openapi.Schema {
+	for bodyType.Kind() == reflect.Ptr {
+		bodyType = bodyType.Elem()
+	}
+	schema := s.getSchemaType(bodyType)
+	if schema == nil {
+		return &openapi.Schema{}
+	}
+	return schema
+}
+
 func (s *SwaggerGenerator) generateSchemaBodyParam(name string, reqType reflect.Type) openapi.Parameter {
 	param := openapi.Parameter{}
 	param.Name = name
 	param.In = "body"
 	param.Required = true
-	defName := getDefinitionName(reqType)
-	param.Schema = openapi.RefSchema(
-		fmt.Sprintf("#/definitions/%s", defName),
-	)
+	param.Schema = s.definitionRef(reqType)
 	return param
 }
 
@@ -255,5 +373,16 @@ func GetFieldInfo(field reflect.StructField) *fieldInfo {
 	if len(tagParts) > 1 {
 		res.In = tagParts[1]
 	}
+	if len(tagParts) > 2 {
+		res.CollectionFormat = tagParts[2]
+	}
+	for _, option := range tagJsonParts[1:] {
+		switch option {
+		case "omitempty":
+			res.OmitEmpty = true
+		case "string":
+			res.AsString = true
+		}
+	}
 	return res
 }
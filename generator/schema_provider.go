@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"reflect"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+// SchemaProvider is implemented by types describing their own schema, like money values or flexible IDs.
+// Returned schema is used verbatim
+type SchemaProvider interface {
+	JSONSchema() openapi.Schema
+}
+
+// SchemaResolver returns schema of value type as generator renders it: struct types are referenced
+// and added to definitions
+type SchemaResolver func(value interface{}) openapi.Schema
+
+// ResolvingSchemaProvider is implemented by types which schema refers other types, like tagged unions.
+// Types resolved by resolve are added to definitions
+type ResolvingSchemaProvider interface {
+	JSONSchemaWith(resolve SchemaResolver) openapi.Schema
+}
+
+// SchemaFunc builds schema of registered type
+type SchemaFunc func(resolve SchemaResolver) openapi.Schema
+
+var (
+	schemaProviderIface          = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+	resolvingSchemaProviderIface = reflect.TypeOf((*ResolvingSchemaProvider)(nil)).Elem()
+)
+
+// RegisterFunc sets function building schema of value type. Used for types which cannot implement
+// SchemaProvider, like types of other packages
+func (r *TypeRegistry) RegisterFunc(value interface{}, fn SchemaFunc) *TypeRegistry {
+	r.providers[reflect.TypeOf(value)] = fn
+	return r
+}
+
+// providedSchema returns schema built by function registered for type or by type itself
+// if it implements SchemaProvider or ResolvingSchemaProvider
+func (r *TypeRegistry) providedSchema(t reflect.Type, resolve SchemaResolver) (*openapi.Schema, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if r != nil {
+		if fn, ok := r.providers[t]; ok {
+			schema := fn(resolve)
+			return &schema, true
+		}
+	}
+	if t.Kind() == reflect.Interface {
+		return nil, false
+	}
+	ptrType := reflect.PtrTo(t)
+	switch {
+	case ptrType.Implements(resolvingSchemaProviderIface):
+		provider := reflect.New(t).Interface().(ResolvingSchemaProvider)
+		schema := provider.JSONSchemaWith(resolve)
+		return &schema, true
+	case ptrType.Implements(schemaProviderIface):
+		provider := reflect.New(t).Interface().(SchemaProvider)
+		schema := provider.JSONSchema()
+		return &schema, true
+	}
+	return nil, false
+}
+
+// resolveSchema is SchemaResolver of generator
+func (s *SwaggerGenerator) resolveSchema(value interface{}) openapi.Schema {
+	if value == nil {
+		return openapi.Schema{}
+	}
+	schema := s.getSchemaType(reflect.TypeOf(value))
+	if schema == nil {
+		return openapi.Schema{}
+	}
+	return *schema
+}
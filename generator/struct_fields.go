@@ -0,0 +1,205 @@
+package generator
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+// JSONFields returns fields of struct type visible in its JSON encoding, including fields promoted from
+// embedded structs. Promotion follows encoding/json rules: untagged embedded structs are flattened,
+// shallower fields shadow deeper ones, tagged field wins at the same depth and other conflicting fields
+// are dropped. Index of returned fields is path from struct type, like reflect.Type.FieldByIndex accepts
+func JSONFields(t reflect.Type) []reflect.StructField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	candidates := make([]jsonField, 0)
+	visited := make(map[reflect.Type]bool)
+	next := []embedded{{typ: t}}
+	for len(next) > 0 {
+		current := next
+		next = nil
+		// Types embedded several times at the same depth annihilate each other fields
+		count := make(map[reflect.Type]int)
+		for _, emb := range current {
+			count[emb.typ]++
+		}
+		for _, emb := range current {
+			if visited[emb.typ] {
+				continue
+			}
+			visited[emb.typ] = true
+			for i := 0; i < emb.typ.NumField(); i++ {
+				field := emb.typ.Field(i)
+				fType := field.Type
+				if fType.Kind() == reflect.Ptr {
+					fType = fType.Elem()
+				}
+				if field.Anonymous {
+					// Embedded unexported struct types may have exported fields
+					if !field.IsExported() && fType.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !field.IsExported() {
+					continue
+				}
+				fInfo := GetFieldInfo(field)
+				if fInfo == nil {
+					continue
+				}
+				field.Index = append(append([]int{}, emb.index...), i)
+				tagged := jsonTagName(field) != ""
+				if tagged || !field.Anonymous || fType.Kind() != reflect.Struct {
+					c := jsonField{field: field, name: fInfo.JSONName, tagged: tagged}
+					candidates = append(candidates, c)
+					if count[emb.typ] > 1 {
+						candidates = append(candidates, c)
+					}
+					continue
+				}
+				next = append(next, embedded{typ: fType, index: field.Index})
+			}
+		}
+	}
+
+	byName := make(map[string][]jsonField)
+	names := make([]string, 0)
+	for _, c := range candidates {
+		if _, ok := byName[c.name]; !ok {
+			names = append(names, c.name)
+		}
+		byName[c.name] = append(byName[c.name], c)
+	}
+	fields := make([]reflect.StructField, 0, len(names))
+	for _, name := range names {
+		if field, ok := dominantField(byName[name]); ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return indexLess(fields[i].Index, fields[j].Index)
+	})
+	return fields
+}
+
+// jsonField is candidate field of JSON encoding
+type jsonField struct {
+	field  reflect.StructField
+	name   string
+	tagged bool
+}
+
+// dominantField returns field hiding other fields with the same name: the shallowest one, tagged if
+// there are several. ok is false if several fields are equally dominant
+func dominantField(fields []jsonField) (reflect.StructField, bool) {
+	depth := len(fields[0].field.Index)
+	for _, f := range fields[1:] {
+		if len(f.field.Index) < depth {
+			depth = len(f.field.Index)
+		}
+	}
+	var dominant *jsonField
+	ambiguous := false
+	for i, f := range fields {
+		if len(f.field.Index) != depth {
+			continue
+		}
+		switch {
+		case dominant == nil:
+			dominant = &fields[i]
+		case f.tagged && !dominant.tagged:
+			dominant = &fields[i]
+			ambiguous = false
+		case f.tagged == dominant.tagged:
+			ambiguous = true
+		}
+	}
+	if ambiguous {
+		return reflect.StructField{}, false
+	}
+	return dominant.field, true
+}
+
+// jsonTagName returns name set by json tag of field
+func jsonTagName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+func indexLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// SetEmbedsAsAllOf enables rendering of embedded structs as allOf composition of their definitions
+// and own struct fields. By default fields of embedded structs are flattened like encoding/json does
+func (s *SwaggerGenerator) SetEmbedsAsAllOf(enabled bool) {
+	s.embedsAllOf = enabled
+}
+
+// embeddedSchemas returns own fields of struct type and schemas of its embedded structs. Embedded struct is
+// referenced only when all its fields are promoted, embeds with fields shadowed by shallower fields or dropped
+// as ambiguous are flattened, their definitions would require properties missing in JSON
+func (s *SwaggerGenerator) embeddedSchemas(t reflect.Type, fields []reflect.StructField) ([]reflect.StructField, []openapi.Schema) {
+	promoted := make(map[string][]int, len(fields))
+	for _, field := range fields {
+		promoted[GetFieldInfo(field).JSONName] = field.Index
+	}
+	referenced := make(map[int]bool)
+	embeds := make([]openapi.Schema, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fType := field.Type
+		if fType.Kind() == reflect.Ptr {
+			fType = fType.Elem()
+		}
+		if !field.Anonymous || fType.Kind() != reflect.Struct || jsonTagName(field) != "" || GetFieldInfo(field) == nil {
+			continue
+		}
+		if !embedPromoted(i, fType, promoted) {
+			continue
+		}
+		if schema := s.getSchemaType(fType); schema != nil {
+			embeds = append(embeds, *schema)
+			referenced[i] = true
+		}
+	}
+	ownFields := make([]reflect.StructField, 0, len(fields))
+	for _, field := range fields {
+		if len(field.Index) == 1 || !referenced[field.Index[0]] {
+			ownFields = append(ownFields, field)
+		}
+	}
+	return ownFields, embeds
+}
+
+// embedPromoted reports if all JSON fields of struct embedded at index are promoted to embedding struct
+func embedPromoted(index int, embedType reflect.Type, promoted map[string][]int) bool {
+	for _, field := range JSONFields(embedType) {
+		fieldIndex, ok := promoted[GetFieldInfo(field).JSONName]
+		if !ok || len(fieldIndex) != len(field.Index)+1 || fieldIndex[0] != index {
+			return false
+		}
+		for j, x := range field.Index {
+			if fieldIndex[j+1] != x {
+				return false
+			}
+		}
+	}
+	return true
+}
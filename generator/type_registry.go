@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"reflect"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+// defaultTypeSchemas are schemas of types encoded as strings
+var defaultTypeSchemas = map[reflect.Type]openapi.Schema{
+	timeType: *openapi.DateTimeProperty(),
+	uuidType: *UUIDProperty(),
+}
+
+// TypeRegistry maps Go types to schemas used instead of schemas derived from type structure.
+// Registered schemas are used for definitions properties, request parameters and response bodies.
+// Methods of nil registry use only default schemas of time.Time and uuid.UUID
+type TypeRegistry struct {
+	schemas   map[reflect.Type]openapi.Schema
+	providers map[reflect.Type]SchemaFunc
+	unions    map[reflect.Type]*Union
+	// variants holds discriminator values of struct types implementing unions
+	variants map[reflect.Type][]unionVariant
+}
+
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		schemas:   make(map[reflect.Type]openapi.Schema),
+		providers: make(map[reflect.Type]SchemaFunc),
+		unions:    make(map[reflect.Type]*Union),
+		variants:  make(map[reflect.Type][]unionVariant),
+	}
+}
+
+// Register sets schema of value type, like decimal.Decimal{}
+func (r *TypeRegistry) Register(value interface{}, schema openapi.Schema) *TypeRegistry {
+	return r.RegisterType(reflect.TypeOf(value), schema)
+}
+
+// RegisterType sets schema of type
+func (r *TypeRegistry) RegisterType(t reflect.Type, schema openapi.Schema) *TypeRegistry {
+	r.schemas[t] = schema
+	return r
+}
+
+// Schema returns copy of schema registered for type or its pointer element type
+func (r *TypeRegistry) Schema(t reflect.Type) (*openapi.Schema, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var schema openapi.Schema
+	ok := false
+	if r != nil {
+		schema, ok = r.schemas[t]
+	}
+	if !ok {
+		schema, ok = defaultTypeSchemas[t]
+	}
+	if !ok {
+		// Struct wrapping registered type, like type UserID struct{ uuid.UUID }, has the same schema when it is
+		// encoded by promoted marshaler methods. Other wrappers, like struct{ sql.NullString }, are flattened
+		if t.Kind() == reflect.Struct && t.NumField() == 1 && t.Field(0).Anonymous && isMarshaler(t) {
+			return r.Schema(t.Field(0).Type)
+		}
+		return nil, false
+	}
+	return &schema, true
+}
+
+// ParamType returns swagger type and format of parameter. Types with registered or provided primitive schema
+// are simple parameters, optional types are described by element type, other types are resolved by GetParamType
+func (r *TypeRegistry) ParamType(t reflect.Type) (typeName string, format string, ok bool) {
+	if elem, _, optional := OptionalElem(t); optional {
+		return r.ParamType(elem)
+	}
+	schema, registered := r.Schema(t)
+	if !registered {
+		schema, registered = r.providedSchema(t, func(interface{}) openapi.Schema {
+			return openapi.Schema{}
+		})
+	}
+	if registered {
+		if len(schema.Type) != 1 || schema.Type[0] == "object" || schema.Type[0] == "array" {
+			return "", "", false
+		}
+		return schema.Type[0], schema.Format, true
+	}
+	return GetParamType(t)
+}
+
+// ParamKind returns how field of type can be bound from request parameters, taking registered schemas into account
+func (r *TypeRegistry) ParamKind(t reflect.Type) ParamKind {
+	if elem, _, optional := OptionalElem(t); optional {
+		// Optional parameters are bound from single value
+		if r.ParamKind(elem) == SimpleParam {
+			return SimpleParam
+		}
+		return UnsupportedParam
+	}
+	if _, _, ok := r.ParamType(t); ok {
+		return SimpleParam
+	}
+	if _, registered := r.Schema(t); registered {
+		return UnsupportedParam
+	}
+	if r.hasProvider(t) {
+		return UnsupportedParam
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		if _, _, ok := r.ParamType(t.Elem()); ok {
+			return ArrayParam
+		}
+	case reflect.Struct:
+		return ObjectParam
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return UnsupportedParam
+		}
+		elemKind := r.ParamKind(t.Elem())
+		if elemKind == SimpleParam || elemKind == ArrayParam {
+			return ObjectParam
+		}
+	}
+	return UnsupportedParam
+}
+
+// hasProvider reports if schema of type is built by registered function or type itself
+func (r *TypeRegistry) hasProvider(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if r != nil {
+		if _, ok := r.providers[t]; ok {
+			return true
+		}
+	}
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	ptrType := reflect.PtrTo(t)
+	return ptrType.Implements(schemaProviderIface) || ptrType.Implements(resolvingSchemaProviderIface)
+}
+
+// SetTypeRegistry sets registry of types schemas
+func (s *SwaggerGenerator) SetTypeRegistry(registry *TypeRegistry) {
+	s.types = registry
+}
@@ -0,0 +1,194 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/AlhimicMan/goswag/generator/openapi3"
+	openapi "github.com/go-openapi/spec"
+)
+
+// Union describes implementations of interface type distinguished by discriminator property of JSON object
+type Union struct {
+	Discriminator string
+	// Variants maps discriminator values to implementation types, like ClickEvent or *ClickEvent
+	Variants map[string]reflect.Type
+}
+
+// Values returns discriminator values in sorted order
+func (u *Union) Values() []string {
+	values := make([]string, 0, len(u.Variants))
+	for value := range u.Variants {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// unionVariant is discriminator property value of struct type implementing union interface
+type unionVariant struct {
+	iface         reflect.Type
+	discriminator string
+	value         string
+}
+
+// RegisterUnion sets implementations of interface type, given by pointer like (*Event)(nil).
+// variants maps discriminator values to values of implementation types, like {"click": ClickEvent{}}.
+// Fields of interface type are documented as oneOf variants definitions in OpenAPI 3, in Swagger 2.0 variants
+// definitions extend interface definition by allOf. Variants definitions have required discriminator property.
+// Variants should declare discriminator field to encode it in responses
+func (r *TypeRegistry) RegisterUnion(iface interface{}, discriminator string, variants map[string]interface{}) error {
+	ifaceType := reflect.TypeOf(iface)
+	if ifaceType == nil || ifaceType.Kind() != reflect.Ptr || ifaceType.Elem().Kind() != reflect.Interface {
+		return fmt.Errorf("cannot register union: %v is not pointer to interface", ifaceType)
+	}
+	ifaceType = ifaceType.Elem()
+	if discriminator == "" {
+		return fmt.Errorf("cannot register union %s: empty discriminator", ifaceType.String())
+	}
+	union := &Union{
+		Discriminator: discriminator,
+		Variants:      make(map[string]reflect.Type, len(variants)),
+	}
+	for value, variant := range variants {
+		variantType := reflect.TypeOf(variant)
+		if variantType == nil || !variantType.Implements(ifaceType) {
+			return fmt.Errorf("cannot register union %s: %v does not implement it", ifaceType.String(), variantType)
+		}
+		structType := variantType
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			return fmt.Errorf("cannot register union %s: variant %s is not struct", ifaceType.String(), variantType.String())
+		}
+		union.Variants[value] = variantType
+	}
+	for value, variantType := range union.Variants {
+		structType := variantType
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		r.variants[structType] = append(r.variants[structType], unionVariant{
+			iface:         ifaceType,
+			discriminator: discriminator,
+			value:         value,
+		})
+	}
+	r.unions[ifaceType] = union
+	return nil
+}
+
+// Union returns implementations of interface type
+func (r *TypeRegistry) Union(t reflect.Type) (*Union, bool) {
+	if r == nil || t.Kind() != reflect.Interface {
+		return nil, false
+	}
+	union, ok := r.unions[t]
+	return union, ok
+}
+
+// unionSchema returns oneOf schema of union variants with discriminator. Mapping of discriminator values
+// is set to extension converted to OpenAPI 3 discriminator mapping. Swagger 2.0 has no oneOf, union is
+// documented as base definition with discriminator, which variants definitions extend
+func (s *SwaggerGenerator) unionSchema(union *Union) *openapi.Schema {
+	schema := &openapi.Schema{}
+	schema.Type = []string{"object"}
+	schema.Properties = map[string]openapi.Schema{
+		union.Discriminator: *openapi.StringProperty(),
+	}
+	schema.Required = []string{union.Discriminator}
+	schema.Discriminator = union.Discriminator
+	if !s.openAPI3 {
+		prop := schema.Properties[union.Discriminator]
+		for _, value := range union.Values() {
+			prop.Enum = append(prop.Enum, value)
+			// Variants are not referenced by base definition, so they are added to definitions explicitly
+			s.getSchemaType(union.Variants[value])
+		}
+		schema.Properties[union.Discriminator] = prop
+		return schema
+	}
+	mapping := make(map[string]string, len(union.Variants))
+	for _, value := range union.Values() {
+		ref := s.getSchemaType(union.Variants[value])
+		if ref == nil {
+			continue
+		}
+		schema.OneOf = append(schema.OneOf, *ref)
+		mapping[value] = ref.Ref.String()
+	}
+	schema.AddExtension(openapi3.DiscriminatorMappingExtension, mapping)
+	return schema
+}
+
+// applyUnionVariants sets discriminator properties of union variant definition. Properties list
+// discriminator values of variant and are required
+func (s *SwaggerGenerator) applyUnionVariants(definitionType reflect.Type, props map[string]openapi.Schema, required []string) []string {
+	if s.types == nil {
+		return required
+	}
+	variants := s.types.variants[definitionType]
+	values := make(map[string][]interface{})
+	for _, variant := range variants {
+		values[variant.discriminator] = append(values[variant.discriminator], variant.value)
+	}
+	discriminators := make([]string, 0, len(values))
+	for discriminator := range values {
+		discriminators = append(discriminators, discriminator)
+	}
+	sort.Strings(discriminators)
+	for _, discriminator := range discriminators {
+		enum := values[discriminator]
+		prop, ok := props[discriminator]
+		if !ok || prop.Ref.String() != "" {
+			prop = *openapi.StringProperty()
+		}
+		sort.Slice(enum, func(i, j int) bool {
+			return enum[i].(string) < enum[j].(string)
+		})
+		prop.Enum = enum
+		props[discriminator] = prop
+		found := false
+		for _, name := range required {
+			if name == discriminator {
+				found = true
+				break
+			}
+		}
+		if !found {
+			required = append(required, discriminator)
+		}
+	}
+	return required
+}
+
+// unionBases returns references to definitions of unions implemented by variant type and discriminator value
+// of variant. Used for Swagger 2.0, where variants definitions extend union definitions
+func (s *SwaggerGenerator) unionBases(definitionType reflect.Type) ([]openapi.Schema, string) {
+	if s.openAPI3 || s.types == nil {
+		return nil, ""
+	}
+	variants := append([]unionVariant{}, s.types.variants[definitionType]...)
+	sort.Slice(variants, func(i, j int) bool {
+		if variants[i].iface != variants[j].iface {
+			return variants[i].iface.String() < variants[j].iface.String()
+		}
+		return variants[i].value < variants[j].value
+	})
+	bases := make([]openapi.Schema, 0, len(variants))
+	added := make(map[reflect.Type]bool)
+	for _, variant := range variants {
+		if added[variant.iface] {
+			continue
+		}
+		added[variant.iface] = true
+		bases = append(bases, *s.definitionRef(variant.iface))
+	}
+	if len(variants) == 0 {
+		return nil, ""
+	}
+	// Swagger 2.0 discriminator value is one per definition
+	return bases, variants[0].value
+}
@@ -0,0 +1,289 @@
+package generator
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	openapi "github.com/go-openapi/spec"
+)
+
+// validateRules are schema constraints parsed from validate tag, like `validate:"required,min=3"`
+type validateRules struct {
+	required    bool
+	format      string
+	validations openapi.CommonValidations
+}
+
+var validateFormats = map[string]string{
+	"email":    "email",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+	"url":      "uri",
+	"uri":      "uri",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+}
+
+var validatePatterns = map[string]string{
+	"alpha":     "^[a-zA-Z]+$",
+	"alphanum":  "^[a-zA-Z0-9]+$",
+	"numeric":   "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"number":    "^[0-9]+$",
+	"lowercase": "^[^A-Z]*$",
+	"uppercase": "^[^a-z]*$",
+}
+
+// parseValidateTag translates validate tag rules to schema constraints. typeName is swagger type of the field,
+// it defines meaning of min and max rules: length for strings, items count for arrays, value for numbers
+func parseValidateTag(tag string, typeName string) validateRules {
+	res := validateRules{}
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "dive" {
+			// Next rules are applied to container items
+			break
+		}
+		if strings.Contains(rule, "|") {
+			// Alternatives cannot be expressed by schema constraints
+			continue
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		if format, ok := validateFormats[name]; ok {
+			res.format = format
+			continue
+		}
+		if pattern, ok := validatePatterns[name]; ok {
+			res.validations.Pattern = pattern
+			continue
+		}
+		switch name {
+		case "required":
+			res.required = true
+		case "min", "gte":
+			res.setMin(param, typeName, false)
+		case "gt":
+			res.setMin(param, typeName, true)
+		case "max", "lte":
+			res.setMax(param, typeName, false)
+		case "lt":
+			res.setMax(param, typeName, true)
+		case "len":
+			res.setMin(param, typeName, false)
+			res.setMax(param, typeName, false)
+		case "oneof":
+			for _, val := range strings.Fields(param) {
+				res.validations.Enum = append(res.validations.Enum, enumValue(val, typeName))
+			}
+		case "unique":
+			res.validations.UniqueItems = true
+		}
+	}
+	return res
+}
+
+func (r *validateRules) setMin(param string, typeName string, exclusive bool) {
+	val, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch typeName {
+	case "string":
+		minLength := int64(val)
+		if exclusive {
+			minLength++
+		}
+		r.validations.MinLength = &minLength
+	case "array":
+		minItems := int64(val)
+		if exclusive {
+			minItems++
+		}
+		r.validations.MinItems = &minItems
+	case "integer", "number":
+		r.validations.Minimum = &val
+		r.validations.ExclusiveMinimum = exclusive
+	}
+}
+
+func (r *validateRules) setMax(param string, typeName string, exclusive bool) {
+	val, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch typeName {
+	case "string":
+		maxLength := int64(val)
+		if exclusive {
+			maxLength--
+		}
+		r.validations.MaxLength = &maxLength
+	case "array":
+		maxItems := int64(val)
+		if exclusive {
+			maxItems--
+		}
+		r.validations.MaxItems = &maxItems
+	case "integer", "number":
+		r.validations.Maximum = &val
+		r.validations.ExclusiveMaximum = exclusive
+	}
+}
+
+func enumValue(val string, typeName string) interface{} {
+	switch typeName {
+	case "integer":
+		if iVal, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return iVal
+		}
+	case "number":
+		if fVal, err := strconv.ParseFloat(val, 64); err == nil {
+			return fVal
+		}
+	}
+	return val
+}
+
+func (r validateRules) applyToSchema(schema *openapi.Schema) {
+	if schema.Ref.String() != "" {
+		// Siblings of $ref are ignored
+		return
+	}
+	if r.format != "" {
+		schema.Format = r.format
+	}
+	v := r.validations
+	if v.Minimum != nil {
+		schema.Minimum = v.Minimum
+		schema.ExclusiveMinimum = v.ExclusiveMinimum
+	}
+	if v.Maximum != nil {
+		schema.Maximum = v.Maximum
+		schema.ExclusiveMaximum = v.ExclusiveMaximum
+	}
+	if v.MinLength != nil {
+		schema.MinLength = v.MinLength
+	}
+	if v.MaxLength != nil {
+		schema.MaxLength = v.MaxLength
+	}
+	if v.MinItems != nil {
+		schema.MinItems = v.MinItems
+	}
+	if v.MaxItems != nil {
+		schema.MaxItems = v.MaxItems
+	}
+	if v.UniqueItems {
+		schema.UniqueItems = true
+	}
+	if v.Pattern != "" {
+		schema.Pattern = v.Pattern
+	}
+	if len(v.Enum) > 0 {
+		schema.Enum = v.Enum
+	}
+}
+
+// applyFieldRules sets constraints from field validate tag to its schema. Returns true for required field
+func applyFieldRules(schema *openapi.Schema, field reflect.StructField) bool {
+	var typeName string
+	if len(schema.Type) > 0 {
+		typeName = schema.Type[0]
+	}
+	if typeName == "string" && numberKind(field.Type) {
+		// Limits of numbers encoded as strings are not lengths and cannot be expressed by string schema
+		typeName = ""
+	}
+	rules := parseValidateTag(field.Tag.Get("validate"), typeName)
+	rules.applyToSchema(schema)
+	return rules.required
+}
+
+func (r validateRules) applyToParam(param *openapi.Parameter) {
+	if r.required {
+		param.Required = true
+	}
+	if r.format != "" {
+		param.Format = r.format
+	}
+	v := r.validations
+	if v.Minimum != nil {
+		param.Minimum = v.Minimum
+		param.ExclusiveMinimum = v.ExclusiveMinimum
+	}
+	if v.Maximum != nil {
+		param.Maximum = v.Maximum
+		param.ExclusiveMaximum = v.ExclusiveMaximum
+	}
+	if v.MinLength != nil {
+		param.MinLength = v.MinLength
+	}
+	if v.MaxLength != nil {
+		param.MaxLength = v.MaxLength
+	}
+	if v.MinItems != nil {
+		param.MinItems = v.MinItems
+	}
+	if v.MaxItems != nil {
+		param.MaxItems = v.MaxItems
+	}
+	if v.UniqueItems {
+		param.UniqueItems = true
+	}
+	if v.Pattern != "" {
+		param.Pattern = v.Pattern
+	}
+	if len(v.Enum) > 0 {
+		param.Enum = v.Enum
+	}
+}
+
+// hasValidateRules checks if struct or its nested structs have fields with validate tag
+func hasValidateRules(paramType reflect.Type) bool {
+	return hasValidateRulesVisited(paramType, make(map[reflect.Type]struct{}))
+}
+
+func hasValidateRulesVisited(paramType reflect.Type, visited map[reflect.Type]struct{}) bool {
+	for paramType.Kind() == reflect.Ptr || paramType.Kind() == reflect.Slice || paramType.Kind() == reflect.Map {
+		paramType = paramType.Elem()
+	}
+	if paramType.Kind() != reflect.Struct {
+		return false
+	}
+	if _, ok := visited[paramType]; ok {
+		return false
+	}
+	visited[paramType] = struct{}{}
+	for i := 0; i < paramType.NumField(); i++ {
+		field := paramType.Field(i)
+		if _, ok := field.Tag.Lookup("validate"); ok {
+			return true
+		}
+		if field.IsExported() && hasValidateRulesVisited(field.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// validationResponseProcessor documents 422 response for operations with validated request
+func (s *SwaggerGenerator) validationResponseProcessor(op *openapi.Operation, handlerInfo HandlerInfo) {
+	if handlerInfo.RequestType == nil || handlerInfo.ValidationErrorType == nil {
+		return
+	}
+	if !hasValidateRules(*handlerInfo.RequestType) {
+		return
+	}
+	errType := *handlerInfo.ValidationErrorType
+	if op.Responses == nil {
+		op.Responses = &openapi.Responses{}
+	}
+	if op.Responses.StatusCodeResponses == nil {
+		op.Responses.StatusCodeResponses = make(map[int]openapi.Response)
+	}
+	resp := openapi.NewResponse().
+		WithDescription(http.StatusText(http.StatusUnprocessableEntity)).
+		WithSchema(s.definitionRef(errType))
+	op.Responses.StatusCodeResponses[http.StatusUnprocessableEntity] = *resp
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONToYAML converts JSON document to YAML keeping keys order of JSON document
+func JSONToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := yamlNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode json: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(node)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode yaml: %w", err)
+	}
+	err = enc.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlNode reads next JSON value from decoder as YAML node
+func yamlNode(dec *json.Decoder) (*yaml.Node, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch val := token.(type) {
+	case json.Delim:
+		node := &yaml.Node{}
+		switch val {
+		case '{':
+			node.Kind = yaml.MappingNode
+			node.Tag = "!!map"
+		case '[':
+			node.Kind = yaml.SequenceNode
+			node.Tag = "!!seq"
+		default:
+			return nil, fmt.Errorf("unexpected delimiter %s", val)
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				keyToken, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyToken.(string)
+				if !ok {
+					return nil, fmt.Errorf("unexpected object key %v", keyToken)
+				}
+				node.Content = append(node.Content, scalarNode("!!str", key))
+			}
+			item, err := yamlNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		// Closing delimiter
+		_, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node, nil
+	case string:
+		return scalarNode("!!str", val), nil
+	case json.Number:
+		if strings.ContainsAny(val.String(), ".eE") {
+			return scalarNode("!!float", val.String()), nil
+		}
+		return scalarNode("!!int", val.String()), nil
+	case bool:
+		if val {
+			return scalarNode("!!bool", "true"), nil
+		}
+		return scalarNode("!!bool", "false"), nil
+	case nil:
+		return scalarNode("!!null", "null"), nil
+	}
+	return nil, fmt.Errorf("unexpected json token %v", token)
+}
+
+func scalarNode(tag string, value string) *yaml.Node {
+	return &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   tag,
+		Value: value,
+	}
+}
@@ -0,0 +1,238 @@
+package wrapper
+
+import (
+	"database/sql"
+	"encoding"
+	"fmt"
+	"github.com/AlhimicMan/goswag/generator"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// bindParam sets request struct field from path, query, header or cookie values.
+// Absent and empty values leave field untouched. Parse errors are reported as 400 response with parameter name
+func bindParam(inputVal reflect.Value, param ReqField, values []string) error {
+	values = nonEmptyValues(values)
+	if len(values) == 0 {
+		return nil
+	}
+	fItem, err := fieldByIndex(inputVal, param.FieldIndex)
+	if err != nil {
+		return invalidParamError(param.ParamName, err)
+	}
+	err = setValues(fItem, values, param.CollectionFormat)
+	if err != nil {
+		return invalidParamError(param.ParamName, err)
+	}
+	return nil
+}
+
+// bindObjectParam sets struct or map field from bracketed query keys: filter[name]=x&filter[age]=3
+func bindObjectParam(inputVal reflect.Value, param ReqField, query url.Values, types *generator.TypeRegistry) error {
+	fItem, err := fieldByIndex(inputVal, param.FieldIndex)
+	if err != nil {
+		return invalidParamError(param.ParamName, err)
+	}
+	return setObjectValue(fItem, param.ParamName, query, types)
+}
+
+// fieldByIndex returns struct field by index path. Nil pointers to embedded structs are allocated
+func fieldByIndex(structVal reflect.Value, index []int) (reflect.Value, error) {
+	fItem := structVal
+	for i, x := range index {
+		if i > 0 && fItem.Kind() == reflect.Ptr {
+			if fItem.IsNil() {
+				if !fItem.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %s", fItem.Type().Elem())
+				}
+				fItem.Set(reflect.New(fItem.Type().Elem()))
+			}
+			fItem = fItem.Elem()
+		}
+		fItem = fItem.Field(x)
+	}
+	return fItem, nil
+}
+
+func invalidParamError(paramName string, err error) error {
+	return ErrorResult{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_parameter",
+		Message: fmt.Sprintf("invalid value for parameter %s: %v", paramName, err),
+	}
+}
+
+func setObjectValue(fItem reflect.Value, prefix string, query url.Values, types *generator.TypeRegistry) error {
+	if fItem.Kind() == reflect.Map {
+		return setMapValue(fItem, prefix, query)
+	}
+	for _, field := range generator.JSONFields(fItem.Type()) {
+		fInfo := generator.GetFieldInfo(field)
+		key := prefix + "[" + fInfo.Name + "]"
+		switch types.ParamKind(field.Type) {
+		case generator.SimpleParam, generator.ArrayParam:
+			values := nonEmptyValues(query[key])
+			if len(values) == 0 {
+				continue
+			}
+			subItem, err := fieldByIndex(fItem, field.Index)
+			if err == nil {
+				err = setValues(subItem, values, fInfo.CollectionFormat)
+			}
+			if err != nil {
+				return invalidParamError(key, err)
+			}
+		case generator.ObjectParam:
+			subItem, err := fieldByIndex(fItem, field.Index)
+			if err != nil {
+				return invalidParamError(key, err)
+			}
+			err = setObjectValue(subItem, key, query, types)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setMapValue(fItem reflect.Value, prefix string, query url.Values) error {
+	mapType := fItem.Type()
+	for key, values := range query {
+		if !strings.HasPrefix(key, prefix+"[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		mapKey := key[len(prefix)+1 : len(key)-1]
+		if strings.ContainsAny(mapKey, "[]") {
+			continue
+		}
+		values = nonEmptyValues(values)
+		if len(values) == 0 {
+			continue
+		}
+		elem := reflect.New(mapType.Elem()).Elem()
+		err := setValues(elem, values, "")
+		if err != nil {
+			return invalidParamError(key, err)
+		}
+		if fItem.IsNil() {
+			fItem.Set(reflect.MakeMap(mapType))
+		}
+		fItem.SetMapIndex(reflect.ValueOf(mapKey).Convert(mapType.Key()), elem)
+	}
+	return nil
+}
+
+// setValues sets field from parameter values. Slices are filled from repeated values,
+// each value is split according to collection format
+func setValues(fItem reflect.Value, values []string, collectionFormat string) error {
+	if fItem.Kind() != reflect.Slice {
+		return setParamValue(fItem, values[0])
+	}
+	items := splitValues(values, collectionFormat)
+	slice := reflect.MakeSlice(fItem.Type(), len(items), len(items))
+	for i, item := range items {
+		err := setParamValue(slice.Index(i), item)
+		if err != nil {
+			return err
+		}
+	}
+	fItem.Set(slice)
+	return nil
+}
+
+func splitValues(values []string, collectionFormat string) []string {
+	var sep string
+	switch collectionFormat {
+	case generator.CollectionMulti:
+		return values
+	case generator.CollectionSSV:
+		sep = " "
+	case generator.CollectionTSV:
+		sep = "\t"
+	case generator.CollectionPipes:
+		sep = "|"
+	default:
+		sep = ","
+	}
+	items := make([]string, 0, len(values))
+	for _, value := range values {
+		items = append(items, nonEmptyValues(strings.Split(value, sep))...)
+	}
+	return items
+}
+
+func nonEmptyValues(values []string) []string {
+	res := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			res = append(res, value)
+		}
+	}
+	return res
+}
+
+func setParamValue(fItem reflect.Value, paramVal string) error {
+	if fItem.Kind() == reflect.Ptr {
+		val := reflect.New(fItem.Type().Elem())
+		err := setParamValue(val.Elem(), paramVal)
+		if err != nil {
+			return err
+		}
+		fItem.Set(val)
+		return nil
+	}
+	if fItem.Type() == durationType {
+		d, err := time.ParseDuration(paramVal)
+		if err != nil {
+			return err
+		}
+		fItem.SetInt(int64(d))
+		return nil
+	}
+	if fItem.CanAddr() {
+		if unmarshaler, ok := fItem.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(paramVal))
+		}
+		if scanner, ok := fItem.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(paramVal)
+		}
+	}
+	switch fItem.Kind() {
+	case reflect.String:
+		fItem.SetString(paramVal)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(paramVal)
+		if err != nil {
+			return err
+		}
+		fItem.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(paramVal, 10, fItem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fItem.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(paramVal, 10, fItem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fItem.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(paramVal, fItem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fItem.SetFloat(v)
+	default:
+		return fmt.Errorf("unsupported parameter type %s", fItem.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package wrapper
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	openapi "github.com/go-openapi/spec"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type typedParamsReq struct {
+	ID       uuid.UUID     `json:"id"`
+	Offset   int           `json:"offset"`
+	Limit    uint8         `json:"limit"`
+	Ratio    float64       `json:"ratio"`
+	Active   bool          `json:"active"`
+	Since    time.Time     `json:"since"`
+	Timeout  time.Duration `json:"timeout"`
+	Optional *int          `json:"optional"`
+}
+
+func serveTest(e *echo.Echo, method, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCallProcessorTypedParams(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/items", "Items")
+	var got typedParamsReq
+	group.GET("/:id", generator.HandlerParameters{}, func(ctx context.Context, req typedParamsReq) (EmptyResp, error) {
+		got = req
+		return EmptyResp{}, nil
+	})
+
+	id := uuid.New()
+	rec := serveTest(e, http.MethodGet, "/items/"+id.String()+
+		"?offset=10&limit=5&ratio=0.5&active=true&since=2023-01-02T03:04:05Z&timeout=1m30s")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, id, got.ID)
+	assert.Equal(t, 10, got.Offset)
+	assert.Equal(t, uint8(5), got.Limit)
+	assert.Equal(t, 0.5, got.Ratio)
+	assert.True(t, got.Active)
+	assert.Equal(t, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), got.Since.UTC())
+	assert.Equal(t, 90*time.Second, got.Timeout)
+	assert.Nil(t, got.Optional)
+
+	rec = serveTest(e, http.MethodGet, "/items/"+id.String()+"?optional=7")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	if assert.NotNil(t, got.Optional) {
+		assert.Equal(t, 7, *got.Optional)
+	}
+}
+
+func TestCallProcessorInvalidParam(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/items", "Items")
+	group.GET("/:id", generator.HandlerParameters{}, func(ctx context.Context, req typedParamsReq) (EmptyResp, error) {
+		return EmptyResp{}, nil
+	})
+
+	rec := serveTest(e, http.MethodGet, "/items/"+uuid.New().String()+"?limit=300")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := make(map[string]interface{})
+	err := json.Unmarshal(rec.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Contains(t, body["detail"], "limit")
+	assert.Equal(t, "invalid_parameter", body["code"])
+
+	rec = serveTest(e, http.MethodGet, "/items/not-uuid")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "id")
+}
+
+type headerCookieReq struct {
+	Tenant  string `param:"X-Tenant-ID,header"`
+	Session string `param:"session,cookie"`
+	Retries int    `param:"X-Retries,header"`
+}
+
+func TestCallProcessorHeaderCookieParams(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/items", "Items")
+	var got headerCookieReq
+	group.GET("", generator.HandlerParameters{}, func(ctx context.Context, req headerCookieReq) (EmptyResp, error) {
+		got = req
+		return EmptyResp{}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("X-Tenant-ID", "tenant-1")
+	req.Header.Set("X-Retries", "3")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "tenant-1", got.Tenant)
+	assert.Equal(t, "abc", got.Session)
+	assert.Equal(t, 3, got.Retries)
+}
+
+type listFilter struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+type arrayParamsReq struct {
+	Status []string       `param:"status,query,multi"`
+	IDs    []int          `param:"ids,query"`
+	Codes  []string       `param:"codes,query,pipes"`
+	Filter listFilter     `json:"filter"`
+	Limits map[string]int `json:"limits"`
+}
+
+func TestCallProcessorArrayAndObjectParams(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/items", "Items")
+	var got arrayParamsReq
+	group.GET("", generator.HandlerParameters{}, func(ctx context.Context, req arrayParamsReq) (EmptyResp, error) {
+		got = req
+		return EmptyResp{}, nil
+	})
+
+	rec := serveTest(e, http.MethodGet, "/items?status=a&status=b&ids=1,2,3&codes=x|y"+
+		"&filter[name]=john&filter[age]=3&limits[daily]=10&limits[monthly]=100")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"a", "b"}, got.Status)
+	assert.Equal(t, []int{1, 2, 3}, got.IDs)
+	assert.Equal(t, []string{"x", "y"}, got.Codes)
+	assert.Equal(t, listFilter{Name: "john", Age: 3}, got.Filter)
+	assert.Equal(t, map[string]int{"daily": 10, "monthly": 100}, got.Limits)
+
+	rec = serveTest(e, http.MethodGet, "/items?filter[age]=old")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "filter[age]")
+}
+
+type registeredTypeReq struct {
+	Name   sql.NullString `json:"name" param:"name,query"`
+	Filter struct {
+		Owner sql.NullString `json:"owner"`
+	} `json:"filter"`
+}
+
+func TestCallProcessorRegisteredType(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	router.RegisterTypeSchema(sql.NullString{}, *openapi.StringProperty())
+	group := router.Group("/items", "Items")
+	var got registeredTypeReq
+	group.GET("", generator.HandlerParameters{}, func(ctx context.Context, req registeredTypeReq) (EmptyResp, error) {
+		got = req
+		return EmptyResp{}, nil
+	})
+
+	rec := serveTest(e, http.MethodGet, "/items?name=box&filter[owner]=bob")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, sql.NullString{String: "box", Valid: true}, got.Name)
+	assert.Equal(t, sql.NullString{String: "bob", Valid: true}, got.Filter.Owner)
+
+	defs, err := router.SchemaGenerator().GetSchema(reflect.TypeOf(registeredTypeReq{}))
+	assert.NoError(t, err)
+	name := defs["#/definitions/wrapper.registeredTypeReq"].Properties["name"]
+	assert.Equal(t, openapi.StringOrArray{"string"}, name.Type)
+}
+
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type sortOptions struct {
+	Sort string `json:"sort"`
+}
+
+type embeddedReq struct {
+	*Pagination
+	sortOptions
+	ID     int `json:"id" param:"id,path"`
+	Offset int `json:"offset" param:"skip"`
+}
+
+func TestCallProcessorEmbeddedFields(t *testing.T) {
+	e := echo.New()
+	group := NewRouter(e).Group("/items", "Items")
+	var got embeddedReq
+	group.GET("/:id", generator.HandlerParameters{}, func(ctx context.Context, req embeddedReq) (EmptyResp, error) {
+		got = req
+		return EmptyResp{}, nil
+	})
+
+	rec := serveTest(e, http.MethodGet, "/items/7?limit=10&skip=20&sort=name")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 7, got.ID)
+	if assert.NotNil(t, got.Pagination) {
+		assert.Equal(t, 10, got.Limit)
+		assert.Equal(t, 0, got.Pagination.Offset)
+	}
+	assert.Equal(t, 20, got.Offset)
+	assert.Equal(t, "name", got.Sort)
+}
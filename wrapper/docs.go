@@ -0,0 +1,225 @@
+package wrapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type DocsUI string
+
+const (
+	SwaggerUI DocsUI = "swagger-ui"
+	ReDoc     DocsUI = "redoc"
+)
+
+const yamlContentType = "application/yaml"
+
+// docsAssets holds Swagger UI files served with documentation page, so page does not depend on CDN
+//
+//go:embed assets/swagger-ui-bundle.js assets/swagger-ui.css
+var docsAssets embed.FS
+
+var docsAssetsTypes = map[string]string{
+	"swagger-ui-bundle.js": "application/javascript; charset=utf-8",
+	"swagger-ui.css":       "text/css; charset=utf-8",
+}
+
+// reDocScriptURL is ReDoc bundle of pinned version
+const reDocScriptURL = "https://cdn.jsdelivr.net/npm/redoc@2.0.0/bundles/redoc.standalone.js"
+
+// DocsOptions configures spec and documentation endpoints
+type DocsOptions struct {
+	// Path is prefix of endpoints. Spec is served at Path/openapi.json and Path/openapi.yaml,
+	// documentation page at Path/docs
+	Path string
+	// UI is documentation page renderer, SwaggerUI by default
+	UI DocsUI
+	// Title of documentation page, spec title by default
+	Title string
+}
+
+type cachedSpec struct {
+	body []byte
+	etag string
+}
+
+// specCache holds generated spec per format. Spec is generated on first request and dropped by router
+// settings and routes changing spec
+type specCache struct {
+	mu    sync.Mutex
+	specs map[string]cachedSpec
+	// errorCodes is number of error registry entries when specs were generated, registry can be filled later
+	errorCodes int
+}
+
+var docsTemplates = map[DocsUI]*template.Template{
+	SwaggerUI: template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.Title}}</title>
+    <link rel="stylesheet" href="{{.AssetsURL}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.AssetsURL}}/swagger-ui-bundle.js"></script>
+<script>
+    window.onload = function () {
+        window.ui = SwaggerUIBundle({url: "{{.SpecURL}}", dom_id: "#swagger-ui"});
+    };
+</script>
+</body>
+</html>
+`)),
+	ReDoc: template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.Title}}</title>
+</head>
+<body>
+<redoc spec-url="{{.SpecURL}}"></redoc>
+<script src="{{.ScriptURL}}"></script>
+</body>
+</html>
+`)),
+}
+
+// MountDocs registers spec endpoints openapi.json, openapi.yaml and documentation page docs under options path.
+// Swagger UI files are embedded and served at assets/.
+// Spec is generated on first request and cached, so routes must be registered before docs are requested.
+// Spec responses have ETag header and support conditional requests
+func (s *RouteWrapper) MountDocs(options DocsOptions, m ...echo.MiddlewareFunc) error {
+	path := strings.TrimSuffix(options.Path, "/")
+	ui := options.UI
+	if ui == "" {
+		ui = SwaggerUI
+	}
+	tmpl, ok := docsTemplates[ui]
+	if !ok {
+		return errors.Errorf("unsupported docs UI: %s", ui)
+	}
+	title := options.Title
+	if title == "" {
+		title = s.specOptions.Title
+	}
+	if title == "" {
+		title = "API documentation"
+	}
+	var page bytes.Buffer
+	err := tmpl.Execute(&page, struct {
+		Title     string
+		SpecURL   string
+		AssetsURL string
+		ScriptURL string
+	}{
+		Title:     title,
+		SpecURL:   path + "/openapi.json",
+		AssetsURL: path + "/assets",
+		ScriptURL: reDocScriptURL,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot render docs page")
+	}
+	s.router.GET(path+"/openapi.json", s.specHandler(FormatJSON, echo.MIMEApplicationJSONCharsetUTF8), m...)
+	s.router.GET(path+"/openapi.yaml", s.specHandler(FormatYAML, yamlContentType), m...)
+	s.router.GET(path+"/docs", func(c echo.Context) error {
+		return c.HTMLBlob(http.StatusOK, page.Bytes())
+	}, m...)
+	if ui == SwaggerUI {
+		s.router.GET(path+"/assets/:name", docsAssetHandler, m...)
+	}
+	return nil
+}
+
+func docsAssetHandler(c echo.Context) error {
+	name := c.Param("name")
+	contentType, ok := docsAssetsTypes[name]
+	if !ok {
+		return echo.ErrNotFound
+	}
+	body, err := docsAssets.ReadFile("assets/" + name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "cannot read docs asset").SetInternal(err)
+	}
+	// Assets are versioned with the module
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=86400")
+	return c.Blob(http.StatusOK, contentType, body)
+}
+
+func (s *RouteWrapper) specHandler(format string, contentType string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		spec, err := s.cachedSpec(format)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "cannot generate spec").SetInternal(err)
+		}
+		c.Response().Header().Set("ETag", spec.etag)
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+		if etagMatches(c.Request().Header.Get("If-None-Match"), spec.etag) {
+			return c.NoContent(http.StatusNotModified)
+		}
+		return c.Blob(http.StatusOK, contentType, spec.body)
+	}
+}
+
+// resetDocsCache drops generated specs after settings or routes changing spec
+func (s *RouteWrapper) resetDocsCache() {
+	s.docsCache.mu.Lock()
+	defer s.docsCache.mu.Unlock()
+	s.docsCache.specs = nil
+}
+
+// cachedSpec returns spec in format, generating it on first call
+func (s *RouteWrapper) cachedSpec(format string) (cachedSpec, error) {
+	s.docsCache.mu.Lock()
+	defer s.docsCache.mu.Unlock()
+	errorCodes := 0
+	if s.errorRegistry != nil {
+		errorCodes = len(s.errorRegistry.entries)
+	}
+	if errorCodes != s.docsCache.errorCodes {
+		s.docsCache.specs = nil
+		s.docsCache.errorCodes = errorCodes
+	}
+	if spec, ok := s.docsCache.specs[format]; ok {
+		return spec, nil
+	}
+	body, err := s.ExportSpec(format)
+	if err != nil {
+		return cachedSpec{}, err
+	}
+	sum := sha256.Sum256(body)
+	spec := cachedSpec{
+		body: body,
+		etag: `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+	if s.docsCache.specs == nil {
+		s.docsCache.specs = make(map[string]cachedSpec)
+	}
+	s.docsCache.specs[format] = spec
+	return spec, nil
+}
+
+// etagMatches checks If-None-Match header value, which can list several weak or strong tags
+func etagMatches(header string, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package wrapper
+
+import (
+	"context"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMountDocs(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e, generator.SpecOptions{Title: "Users API"})
+	err := router.MountDocs(DocsOptions{Path: "/api/"})
+	assert.NoError(t, err)
+	// Routes registered after mount are documented, spec is generated on first request
+	group := router.Group("/users", "Users")
+	group.GET("/:id", generator.HandlerParameters{}, func(ctx context.Context, req exportUser) (exportUser, error) {
+		return req, nil
+	})
+
+	rec := serveTest(e, http.MethodGet, "/api/openapi.json")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "application/json")
+	assert.Contains(t, rec.Body.String(), `"/users/{id}"`)
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	cached := httptest.NewRecorder()
+	e.ServeHTTP(cached, req)
+	assert.Equal(t, http.StatusNotModified, cached.Code)
+	assert.Empty(t, cached.Body.String())
+
+	rec = serveTest(e, http.MethodGet, "/api/openapi.yaml")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/yaml", rec.Header().Get(echo.HeaderContentType))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "swagger: \"2.0\"\n"))
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+
+	rec = serveTest(e, http.MethodGet, "/api/docs")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<title>Users API</title>")
+	assert.Contains(t, rec.Body.String(), "swagger-ui-bundle.js")
+	assert.Contains(t, rec.Body.String(), `\/api\/openapi.json`)
+	assert.NotContains(t, rec.Body.String(), "https://")
+
+	rec = serveTest(e, http.MethodGet, "/api/assets/swagger-ui-bundle.js")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "application/javascript")
+	assert.Contains(t, rec.Body.String(), "SwaggerUIBundle")
+	rec = serveTest(e, http.MethodGet, "/api/assets/swagger-ui.css")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serveTest(e, http.MethodGet, "/api/assets/index.html")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	router.SetSpecOptions(generator.SpecOptions{Title: "Accounts API"})
+	rec = serveTest(e, http.MethodGet, "/api/openapi.json")
+	assert.Contains(t, rec.Body.String(), `"title": "Accounts API"`)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	router.SetSpecVersion(generator.OpenAPI30)
+	rec = serveTest(e, http.MethodGet, "/api/openapi.yaml")
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "openapi: 3.0"))
+
+	// Routes and settings changed after first request are served with new ETag
+	etag = serveTest(e, http.MethodGet, "/api/openapi.json").Header().Get("ETag")
+	group.DELETE("/:id", generator.HandlerParameters{}, func(ctx context.Context, req exportUser) error {
+		return nil
+	})
+	rec = serveTest(e, http.MethodGet, "/api/openapi.json")
+	assert.Contains(t, rec.Body.String(), `"delete"`)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	etag = rec.Header().Get("ETag")
+	router.SetDefinitionNamer(generator.FullPathDefinitionName)
+	rec = serveTest(e, http.MethodGet, "/api/openapi.json")
+	assert.Contains(t, rec.Body.String(), "github.com.AlhimicMan.goswag.wrapper.exportUser")
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	registry := NewErrorRegistry()
+	router.SetErrorRegistry(registry)
+	etag = serveTest(e, http.MethodGet, "/api/openapi.json").Header().Get("ETag")
+	registry.Register(errUserNotFound, ErrorMapping{Status: http.StatusNotFound, Code: "user_not_found"})
+	rec = serveTest(e, http.MethodGet, "/api/openapi.json")
+	assert.Contains(t, rec.Body.String(), "user_not_found")
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+}
+
+func TestMountDocsReDoc(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	assert.NoError(t, router.MountDocs(DocsOptions{UI: ReDoc, Title: "Reference"}))
+	rec := serveTest(e, http.MethodGet, "/docs")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<redoc spec-url="/openapi.json">`)
+	assert.Contains(t, rec.Body.String(), "<title>Reference</title>")
+	assert.Contains(t, rec.Body.String(), "redoc@2.0.0")
+
+	assert.Error(t, router.MountDocs(DocsOptions{UI: "rapidoc"}))
+}
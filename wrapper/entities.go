@@ -6,12 +6,16 @@ import (
 )
 
 type WrapGroup struct {
-	echoGroup *echo.Group
+	echoGroup    *echo.Group
+	routeWrapper *RouteWrapper
 
 	path           string
 	routesHandlers map[string]generator.RouteInfo
 	tags           []string
+	tagInfo        generator.TagInfo
 	childGroups    map[string]*WrapGroup
+	// defaultResponses are documented for all group routes unless route declares response with the same status
+	defaultResponses []generator.ResponseParameters
 }
 
 type EmptyReq struct{}
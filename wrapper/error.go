@@ -5,8 +5,19 @@ import (
 	"fmt"
 )
 
+// StatusCoder is implemented by errors defining HTTP status of response
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// ErrorCoder is implemented by errors defining machine-readable error code of response
+type ErrorCoder interface {
+	ErrorCode() string
+}
+
 type ErrorResult struct {
 	Status  int
+	Code    string
 	Message interface{}
 }
 
@@ -17,3 +28,11 @@ func (e ErrorResult) Error() string {
 	}
 	return fmt.Sprintf("Status: %d, Message: %s", e.Status, string(msgRes))
 }
+
+func (e ErrorResult) StatusCode() int {
+	return e.Status
+}
+
+func (e ErrorResult) ErrorCode() string {
+	return e.Code
+}
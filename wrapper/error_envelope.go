@@ -0,0 +1,185 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const problemContentType = "application/problem+json"
+
+// ErrorInfo describes handler error resolved to HTTP response
+type ErrorInfo struct {
+	Status int
+	// Code is machine-readable error code, derived from status when error does not define it
+	Code string
+	// Message is public error description. Internal details of 5xx errors are not exposed
+	Message   interface{}
+	RequestID string
+	// Errors lists invalid fields for validation errors
+	Errors []FieldError
+	Err    error
+}
+
+// ErrorEnvelope builds response body for handler error. Returns response content type and body
+type ErrorEnvelope func(c echo.Context, info ErrorInfo) (contentType string, body interface{})
+
+// ProblemDetails is RFC 7807 error response body
+type ProblemDetails struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    interface{}  `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// ProblemEnvelope renders errors as application/problem+json. Used by default
+func ProblemEnvelope(c echo.Context, info ErrorInfo) (string, interface{}) {
+	body := ProblemDetails{
+		Type:      "about:blank",
+		Title:     http.StatusText(info.Status),
+		Status:    info.Status,
+		Detail:    info.Message,
+		Instance:  c.Request().URL.Path,
+		Code:      info.Code,
+		RequestID: info.RequestID,
+		Errors:    info.Errors,
+	}
+	return problemContentType, body
+}
+
+// SimpleError is error response body rendered by SimpleEnvelope
+type SimpleError struct {
+	Code      string       `json:"code"`
+	Message   interface{}  `json:"message"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// SimpleEnvelope renders errors as plain application/json object with code and message
+func SimpleEnvelope(_ echo.Context, info ErrorInfo) (string, interface{}) {
+	body := SimpleError{
+		Code:      info.Code,
+		Message:   info.Message,
+		RequestID: info.RequestID,
+		Errors:    info.Errors,
+	}
+	return echo.MIMEApplicationJSON, body
+}
+
+// SetErrorEnvelope sets format of error responses for all routes of the router. body is value of type
+// returned by envelope, like SimpleError{}, documented for validation errors. Nil body is not documented
+func (s *RouteWrapper) SetErrorEnvelope(envelope ErrorEnvelope, body interface{}) {
+	s.errorEnvelope = envelope
+	s.errorBodyType = reflect.TypeOf(body)
+	s.resetDocsCache()
+}
+
+// writeError resolves response status and code from error and renders it with router error envelope
+func (s *RouteWrapper) writeError(c echo.Context, err error) error {
+	info := s.resolveError(err)
+	info.RequestID = requestID(c)
+	envelope := s.errorEnvelope
+	if envelope == nil {
+		envelope = ProblemEnvelope
+	}
+	contentType, body := envelope(c, info)
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	return s.writeJSON(c, info.Status, body)
+}
+
+// validationErrorBodyType returns type of body rendered by router error envelope for validation errors,
+// set with envelope
+func (s *RouteWrapper) validationErrorBodyType() *reflect.Type {
+	if s.errorEnvelope == nil {
+		bodyType := reflect.TypeOf(ProblemDetails{})
+		return &bodyType
+	}
+	if s.errorBodyType == nil {
+		return nil
+	}
+	bodyType := s.errorBodyType
+	return &bodyType
+}
+
+func (s *RouteWrapper) resolveError(err error) ErrorInfo {
+	info := ErrorInfo{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	if s.errorRegistry != nil {
+		if mapping, ok := s.errorRegistry.match(err); ok {
+			info.Status = mapping.Status
+			info.Code = mapping.Code
+			info.Message = mapping.Message
+			return info
+		}
+	}
+	var errResult ErrorResult
+	var errResultPtr *ErrorResult
+	var validationErr ValidationError
+	var httpErr *echo.HTTPError
+	var statusCoder StatusCoder
+	switch {
+	case errors.As(err, &errResult):
+		info.Status = errResult.Status
+		info.Message = errResult.Message
+	case errors.As(err, &errResultPtr):
+		info.Status = errResultPtr.Status
+		info.Message = errResultPtr.Message
+	case errors.As(err, &validationErr):
+		info.Status = http.StatusUnprocessableEntity
+		info.Message = validationErr.Message
+		info.Errors = validationErr.Errors
+	case errors.As(err, &httpErr):
+		info.Status = httpErr.Code
+		info.Message = httpErr.Message
+	case errors.As(err, &statusCoder):
+		info.Status = statusCoder.StatusCode()
+		info.Message = err.Error()
+	}
+	if info.Status < 400 || info.Status > 599 {
+		info.Status = http.StatusInternalServerError
+	}
+	var errorCoder ErrorCoder
+	if errors.As(err, &errorCoder) {
+		info.Code = errorCoder.ErrorCode()
+	}
+	if info.Code == "" {
+		info.Code = statusErrorCode(info.Status)
+	}
+	if info.Status >= http.StatusInternalServerError {
+		// Messages of server errors may contain internal details, only registry mappings are public
+		info.Message = http.StatusText(info.Status)
+	}
+	return info
+}
+
+// statusErrorCode derives error code from status text: 404 becomes not_found
+func statusErrorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return fmt.Sprintf("http_%d", status)
+	}
+	return strings.ReplaceAll(strings.ToLower(strings.ReplaceAll(text, "-", " ")), " ", "_")
+}
+
+// requestID returns ID set by echo RequestID middleware or sent by client. Generates new one if missing
+func requestID(c echo.Context) string {
+	reqID := c.Response().Header().Get(echo.HeaderXRequestID)
+	if reqID == "" {
+		reqID = c.Request().Header.Get(echo.HeaderXRequestID)
+	}
+	if reqID == "" {
+		reqID = uuid.New().String()
+		c.Response().Header().Set(echo.HeaderXRequestID, reqID)
+	}
+	return reqID
+}
@@ -0,0 +1,120 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+type errorReq struct {
+	Kind string `param:"kind,query"`
+}
+
+func errorHandler(ctx context.Context, req errorReq) (EmptyResp, *ErrorResult) {
+	switch req.Kind {
+	case "not_found":
+		return EmptyResp{}, &ErrorResult{Status: http.StatusNotFound, Code: "user_not_found", Message: "user not found"}
+	case "conflict":
+		return EmptyResp{}, &ErrorResult{Status: http.StatusConflict, Message: "login duplicated"}
+	}
+	return EmptyResp{}, nil
+}
+
+type unavailableError struct{}
+
+func (unavailableError) Error() string {
+	return "replica secret-db-2 is down"
+}
+
+func (unavailableError) StatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
+func wrappedErrorHandler(ctx context.Context, req errorReq) (EmptyResp, error) {
+	switch req.Kind {
+	case "wrapped":
+		return EmptyResp{}, fmt.Errorf("store failed: %w", ErrorResult{Status: http.StatusGone, Message: "gone"})
+	case "unavailable":
+		return EmptyResp{}, unavailableError{}
+	case "bad_gateway":
+		return EmptyResp{}, ErrorResult{Status: http.StatusBadGateway, Message: "upstream secret token rejected"}
+	case "http":
+		return EmptyResp{}, echo.NewHTTPError(http.StatusInternalServerError, "secret query failed")
+	}
+	return EmptyResp{}, errors.New("database password is secret")
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/errors", "Errors")
+	group.GET("/result", generator.HandlerParameters{}, errorHandler)
+	group.GET("/plain", generator.HandlerParameters{}, wrappedErrorHandler)
+
+	rec := serveTest(e, http.MethodGet, "/errors/result")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveTest(e, http.MethodGet, "/errors/result?kind=not_found")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, problemContentType, rec.Header().Get(echo.HeaderContentType))
+	problem := ProblemDetails{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "user_not_found", problem.Code)
+	assert.Equal(t, "user not found", problem.Detail)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, "/errors/result", problem.Instance)
+	assert.NotEmpty(t, problem.RequestID)
+	assert.Equal(t, problem.RequestID, rec.Header().Get(echo.HeaderXRequestID))
+
+	rec = serveTest(e, http.MethodGet, "/errors/result?kind=conflict")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	problem = ProblemDetails{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "conflict", problem.Code)
+
+	rec = serveTest(e, http.MethodGet, "/errors/plain?kind=wrapped")
+	assert.Equal(t, http.StatusGone, rec.Code)
+
+	rec = serveTest(e, http.MethodGet, "/errors/plain")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+	problem = ProblemDetails{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "internal_server_error", problem.Code)
+
+	// Messages of server errors are replaced by status text
+	for kind, status := range map[string]int{
+		"unavailable": http.StatusServiceUnavailable,
+		"bad_gateway": http.StatusBadGateway,
+		"http":        http.StatusInternalServerError,
+	} {
+		rec = serveTest(e, http.MethodGet, "/errors/plain?kind="+kind)
+		assert.Equal(t, status, rec.Code, kind)
+		assert.NotContains(t, rec.Body.String(), "secret", kind)
+		problem = ProblemDetails{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, http.StatusText(status), problem.Detail, kind)
+	}
+}
+
+func TestSimpleErrorEnvelope(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	router.SetErrorEnvelope(SimpleEnvelope, SimpleError{})
+	group := router.Group("/errors", "Errors")
+	group.GET("/result", generator.HandlerParameters{}, errorHandler)
+
+	rec := serveTest(e, http.MethodGet, "/errors/result?kind=not_found")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+	res := SimpleError{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, "user_not_found", res.Code)
+	assert.Equal(t, "user not found", res.Message)
+}
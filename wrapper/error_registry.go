@@ -0,0 +1,140 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"github.com/AlhimicMan/goswag/generator"
+	"net/http"
+	"reflect"
+)
+
+// ErrorMapping describes HTTP response for domain error
+type ErrorMapping struct {
+	Status int
+	// Code is stable machine-readable error code. Derived from status when empty
+	Code string
+	// Message is public error description. Status text is used when empty, error text is never exposed
+	Message string
+}
+
+type errorEntry struct {
+	target     error
+	targetType reflect.Type
+	mapping    ErrorMapping
+	// derivedCode is set when code is derived from status
+	derivedCode bool
+}
+
+// ErrorRegistry maps sentinel errors and error types returned by handlers to HTTP responses.
+// Entries are checked in registration order
+type ErrorRegistry struct {
+	entries []errorEntry
+}
+
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{
+		entries: make([]errorEntry, 0),
+	}
+}
+
+// Register maps sentinel error to response. Handler error matches if errors.Is(err, target).
+// Panics if target is nil or code is already registered with another status or message
+func (r *ErrorRegistry) Register(target error, mapping ErrorMapping) *ErrorRegistry {
+	if target == nil {
+		panic("cannot register nil error")
+	}
+	return r.add(errorEntry{
+		target:      target,
+		mapping:     normalizeMapping(mapping),
+		derivedCode: mapping.Code == "",
+	})
+}
+
+// RegisterType maps error type to response. target is value of error type, like &NotFoundError{}.
+// Handler error matches if errors.As finds error of this type in chain. Panics if target is nil
+// or code is already registered with another status or message
+func (r *ErrorRegistry) RegisterType(target error, mapping ErrorMapping) *ErrorRegistry {
+	if target == nil {
+		panic("cannot register type of nil error")
+	}
+	return r.add(errorEntry{
+		targetType:  reflect.TypeOf(target),
+		mapping:     normalizeMapping(mapping),
+		derivedCode: mapping.Code == "",
+	})
+}
+
+// add appends entry. Errors can share code only when they have the same public response: the same status
+// and message. Codes derived from status are shared by errors with this status and any messages
+func (r *ErrorRegistry) add(entry errorEntry) *ErrorRegistry {
+	for _, registered := range r.entries {
+		if registered.mapping.Code != entry.mapping.Code {
+			continue
+		}
+		sameMessage := registered.mapping.Message == entry.mapping.Message || (registered.derivedCode && entry.derivedCode)
+		if registered.mapping.Status != entry.mapping.Status || !sameMessage {
+			panic(fmt.Sprintf("error code %s is already registered with another status or message, set distinct codes",
+				entry.mapping.Code))
+		}
+	}
+	r.entries = append(r.entries, entry)
+	return r
+}
+
+// ErrorCodes returns catalogue of registered error codes. Shared codes are listed once, derived code shared
+// by errors with different messages is described by status text
+func (r *ErrorRegistry) ErrorCodes() []generator.ErrorCode {
+	codes := make([]generator.ErrorCode, 0, len(r.entries))
+	listed := make(map[string]int)
+	for _, entry := range r.entries {
+		if i, ok := listed[entry.mapping.Code]; ok {
+			if codes[i].Message != entry.mapping.Message {
+				codes[i].Message = http.StatusText(entry.mapping.Status)
+			}
+			continue
+		}
+		listed[entry.mapping.Code] = len(codes)
+		codes = append(codes, generator.ErrorCode{
+			Code:    entry.mapping.Code,
+			Status:  entry.mapping.Status,
+			Message: entry.mapping.Message,
+		})
+	}
+	return codes
+}
+
+func (r *ErrorRegistry) match(err error) (ErrorMapping, bool) {
+	for _, entry := range r.entries {
+		if entry.target != nil {
+			if errors.Is(err, entry.target) {
+				return entry.mapping, true
+			}
+			continue
+		}
+		target := reflect.New(entry.targetType)
+		if errors.As(err, target.Interface()) {
+			return entry.mapping, true
+		}
+	}
+	return ErrorMapping{}, false
+}
+
+func normalizeMapping(mapping ErrorMapping) ErrorMapping {
+	if mapping.Status == 0 {
+		mapping.Status = http.StatusInternalServerError
+	}
+	if mapping.Code == "" {
+		mapping.Code = statusErrorCode(mapping.Status)
+	}
+	if mapping.Message == "" {
+		mapping.Message = http.StatusText(mapping.Status)
+	}
+	return mapping
+}
+
+// SetErrorRegistry sets mapping of domain errors to responses for all routes of the router.
+// Registered error codes are exported to generated spec
+func (s *RouteWrapper) SetErrorRegistry(registry *ErrorRegistry) {
+	s.errorRegistry = registry
+	s.resetDocsCache()
+}
@@ -0,0 +1,111 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+type quotaError struct {
+	Limit int
+}
+
+func (e *quotaError) Error() string {
+	return fmt.Sprintf("quota %d exceeded", e.Limit)
+}
+
+func registryHandler(ctx context.Context, req errorReq) (EmptyResp, error) {
+	switch req.Kind {
+	case "not_found":
+		return EmptyResp{}, fmt.Errorf("get user: %w", errUserNotFound)
+	case "quota":
+		return EmptyResp{}, fmt.Errorf("create user: %w", &quotaError{Limit: 10})
+	}
+	return EmptyResp{}, errors.New("unexpected")
+}
+
+func TestErrorRegistry(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	router.SetErrorRegistry(NewErrorRegistry().
+		Register(errUserNotFound, ErrorMapping{Status: http.StatusNotFound, Code: "user_not_found"}).
+		RegisterType(&quotaError{}, ErrorMapping{Status: http.StatusTooManyRequests, Message: "quota exceeded"}))
+	group := router.Group("/users", "Users")
+	group.GET("", generator.HandlerParameters{}, registryHandler)
+
+	testCases := []struct {
+		kind    string
+		status  int
+		code    string
+		message string
+	}{
+		{kind: "not_found", status: http.StatusNotFound, code: "user_not_found", message: "Not Found"},
+		{kind: "quota", status: http.StatusTooManyRequests, code: "too_many_requests", message: "quota exceeded"},
+		{kind: "other", status: http.StatusInternalServerError, code: "internal_server_error", message: "Internal Server Error"},
+	}
+	for _, tc := range testCases {
+		rec := serveTest(e, http.MethodGet, "/users?kind="+tc.kind)
+		assert.Equal(t, tc.status, rec.Code, tc.kind)
+		problem := ProblemDetails{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, tc.code, problem.Code, tc.kind)
+		assert.Equal(t, tc.message, problem.Detail, tc.kind)
+	}
+
+	spec, err := router.ExportSpec(FormatJSON)
+	assert.NoError(t, err)
+	doc := struct {
+		ErrorCodes []generator.ErrorCode `json:"x-error-codes"`
+	}{}
+	assert.NoError(t, json.Unmarshal(spec, &doc))
+	assert.Equal(t, []generator.ErrorCode{
+		{Code: "too_many_requests", Status: http.StatusTooManyRequests, Message: "quota exceeded"},
+		{Code: "user_not_found", Status: http.StatusNotFound, Message: "Not Found"},
+	}, doc.ErrorCodes)
+}
+
+func TestErrorRegistryRejectsInvalidEntries(t *testing.T) {
+	assert.Panics(t, func() {
+		NewErrorRegistry().Register(nil, ErrorMapping{Status: http.StatusNotFound})
+	})
+	assert.Panics(t, func() {
+		NewErrorRegistry().RegisterType(nil, ErrorMapping{Status: http.StatusNotFound})
+	})
+	assert.Panics(t, func() {
+		NewErrorRegistry().
+			Register(errUserNotFound, ErrorMapping{Status: http.StatusNotFound, Code: "missing"}).
+			RegisterType(&quotaError{}, ErrorMapping{Status: http.StatusGone, Code: "missing"})
+	})
+	assert.Panics(t, func() {
+		NewErrorRegistry().
+			Register(errUserNotFound, ErrorMapping{Status: http.StatusNotFound, Code: "missing", Message: "user not found"}).
+			RegisterType(&quotaError{}, ErrorMapping{Status: http.StatusNotFound, Code: "missing", Message: "quota not found"})
+	})
+	assert.Panics(t, func() {
+		NewErrorRegistry().
+			Register(errUserNotFound, ErrorMapping{Status: http.StatusConflict, Code: "not_found"}).
+			RegisterType(&quotaError{}, ErrorMapping{Status: http.StatusNotFound})
+	})
+
+	errOrderNotFound := errors.New("order not found")
+	var registry *ErrorRegistry
+	assert.NotPanics(t, func() {
+		registry = NewErrorRegistry().
+			Register(errUserNotFound, ErrorMapping{Status: http.StatusNotFound, Message: "user not found"}).
+			Register(errOrderNotFound, ErrorMapping{Status: http.StatusNotFound}).
+			RegisterType(&quotaError{}, ErrorMapping{Status: http.StatusTooManyRequests, Code: "quota"}).
+			Register(errors.New("rate limited"), ErrorMapping{Status: http.StatusTooManyRequests, Code: "quota"})
+	})
+	assert.Equal(t, []generator.ErrorCode{
+		{Code: "not_found", Status: http.StatusNotFound, Message: "Not Found"},
+		{Code: "quota", Status: http.StatusTooManyRequests, Message: "Too Many Requests"},
+	}, registry.ErrorCodes())
+}
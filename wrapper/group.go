@@ -5,20 +5,50 @@ import (
 	"github.com/AlhimicMan/goswag/generator"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"sort"
 	"strings"
 )
 
 func (g *WrapGroup) Group(prefix string, tag string, m ...echo.MiddlewareFunc) *WrapGroup {
+	return g.GroupWithTag(prefix, generator.TagInfo{Name: tag}, m...)
+}
+
+// GroupWithTag creates child group of routes tagged with tag. Tag description is added to spec
+func (g *WrapGroup) GroupWithTag(prefix string, tag generator.TagInfo, m ...echo.MiddlewareFunc) *WrapGroup {
 	group := &WrapGroup{
 		echoGroup:      g.echoGroup.Group(prefix, m...),
+		routeWrapper:   g.routeWrapper,
 		path:           g.path + prefix,
 		routesHandlers: make(map[string]generator.RouteInfo),
-		tags:           []string{tag},
+		tags:           []string{tag.Name},
+		tagInfo:        tag,
+		childGroups:    make(map[string]*WrapGroup),
 	}
 	g.childGroups[prefix] = group
+	g.routeWrapper.resetDocsCache()
 	return group
 }
 
+// addRoute adds route documented in spec
+func (g *WrapGroup) addRoute(key string, route generator.RouteInfo) {
+	g.routesHandlers[key] = route
+	g.routeWrapper.resetDocsCache()
+}
+
+// appendTags adds tags of group and its child groups to tags. Child groups are ordered by prefix
+func (g *WrapGroup) appendTags(tags []generator.TagInfo) []generator.TagInfo {
+	tags = append(tags, g.tagInfo)
+	prefixes := make([]string, 0, len(g.childGroups))
+	for prefix := range g.childGroups {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		tags = g.childGroups[prefix].appendTags(tags)
+	}
+	return tags
+}
+
 func (g *WrapGroup) POST(path string, params generator.HandlerParameters, handler interface{}, m ...echo.MiddlewareFunc) *echo.Route {
 	if strings.HasSuffix(path, "/") {
 		path = path[:len(path)-1]
@@ -34,9 +64,9 @@ func (g *WrapGroup) POST(path string, params generator.HandlerParameters, handle
 			Parameters: params,
 		}
 		handlerKey := fmt.Sprintf("%s~%s", routeInfo.Method, fullPath)
-		g.routesHandlers[handlerKey] = routeInfo
+		g.addRoute(handlerKey, routeInfo)
 	}
-	echoHandler := g.callProcessor(fullPath, handler, true)
+	echoHandler := g.callProcessor(fullPath, handler, true, params.SuccessStatus)
 	return g.echoGroup.POST(path, echoHandler, m...)
 }
 
@@ -51,9 +81,9 @@ func (g *WrapGroup) GET(path string, params generator.HandlerParameters, handler
 			Parameters: params,
 		}
 		handlerKey := fmt.Sprintf("%s~%s", routeInfo.Method, fullPath)
-		g.routesHandlers[handlerKey] = routeInfo
+		g.addRoute(handlerKey, routeInfo)
 	}
-	echoHandler := g.callProcessor(fullPath, handler, false)
+	echoHandler := g.callProcessor(fullPath, handler, false, params.SuccessStatus)
 	return g.echoGroup.GET(path, echoHandler, m...)
 }
 
@@ -68,14 +98,14 @@ func (g *WrapGroup) DELETE(path string, params generator.HandlerParameters, hand
 			Parameters: params,
 		}
 		handlerKey := fmt.Sprintf("%s~%s", routeInfo.Method, fullPath)
-		g.routesHandlers[handlerKey] = routeInfo
+		g.addRoute(handlerKey, routeInfo)
 	}
-	echoHandler := g.callProcessor(fullPath, handler, false)
+	echoHandler := g.callProcessor(fullPath, handler, false, params.SuccessStatus)
 	return g.echoGroup.DELETE(path, echoHandler, m...)
 }
 
-func (g *WrapGroup) CONNECT(path string, _ generator.HandlerParameters, handler interface{}, m ...echo.MiddlewareFunc) *echo.Route {
-	echoHandler := g.callProcessor(path, handler, false)
+func (g *WrapGroup) CONNECT(path string, params generator.HandlerParameters, handler interface{}, m ...echo.MiddlewareFunc) *echo.Route {
+	echoHandler := g.callProcessor(path, handler, false, params.SuccessStatus)
 	return g.echoGroup.CONNECT(path, echoHandler, m...)
 }
 
@@ -89,8 +119,8 @@ func (g *WrapGroup) HEAD(path string, params generator.HandlerParameters, handle
 		Parameters: params,
 	}
 	handlerKey := fmt.Sprintf("%s~%s", routeInfo.Method, fullPath)
-	g.routesHandlers[handlerKey] = routeInfo
-	echoHandler := g.callProcessor(fullPath, handler, false)
+	g.addRoute(handlerKey, routeInfo)
+	echoHandler := g.callProcessor(fullPath, handler, false, params.SuccessStatus)
 	return g.echoGroup.HEAD(path, echoHandler, m...)
 }
 
@@ -104,8 +134,8 @@ func (g *WrapGroup) OPTIONS(path string, params generator.HandlerParameters, han
 		Parameters: params,
 	}
 	handlerKey := fmt.Sprintf("%s~%s", routeInfo.Method, fullPath)
-	g.routesHandlers[handlerKey] = routeInfo
-	echoHandler := g.callProcessor(path, handler, false)
+	g.addRoute(handlerKey, routeInfo)
+	echoHandler := g.callProcessor(path, handler, false, params.SuccessStatus)
 	return g.echoGroup.OPTIONS(path, echoHandler, m...)
 }
 
@@ -120,9 +150,9 @@ func (g *WrapGroup) PATCH(path string, params generator.HandlerParameters, handl
 			Parameters: params,
 		}
 		handlerKey := fmt.Sprintf("%s~%s", routeInfo.Method, fullPath)
-		g.routesHandlers[handlerKey] = routeInfo
+		g.addRoute(handlerKey, routeInfo)
 	}
-	echoHandler := g.callProcessor(fullPath, handler, true)
+	echoHandler := g.callProcessor(fullPath, handler, true, params.SuccessStatus)
 	return g.echoGroup.PATCH(path, echoHandler, m...)
 }
 
@@ -137,17 +167,23 @@ func (g *WrapGroup) PUT(path string, params generator.HandlerParameters, handler
 			Parameters: params,
 		}
 		handlerKey := fmt.Sprintf("%s~%s", routeInfo.Method, fullPath)
-		g.routesHandlers[handlerKey] = routeInfo
+		g.addRoute(handlerKey, routeInfo)
 	}
-	echoHandler := g.callProcessor(fullPath, handler, true)
-	return g.echoGroup.PATCH(path, echoHandler, m...)
+	echoHandler := g.callProcessor(fullPath, handler, true, params.SuccessStatus)
+	return g.echoGroup.PUT(path, echoHandler, m...)
 }
 
-func (g *WrapGroup) TRACE(path string, _ generator.HandlerParameters, handler interface{}, m ...echo.MiddlewareFunc) *echo.Route {
-	echoHandler := g.callProcessor(path, handler, false)
+func (g *WrapGroup) TRACE(path string, params generator.HandlerParameters, handler interface{}, m ...echo.MiddlewareFunc) *echo.Route {
+	echoHandler := g.callProcessor(path, handler, false, params.SuccessStatus)
 	return g.echoGroup.TRACE(path, echoHandler, m...)
 }
 
+// SetDefaultResponses sets responses documented for all routes of the group and its child groups
+func (g *WrapGroup) SetDefaultResponses(responses ...generator.ResponseParameters) {
+	g.defaultResponses = responses
+	g.routeWrapper.resetDocsCache()
+}
+
 func (g *WrapGroup) getRoutes() map[string]generator.RouteInfo {
 	routes := make(map[string]generator.RouteInfo)
 	for _, group := range g.childGroups {
@@ -159,5 +195,28 @@ func (g *WrapGroup) getRoutes() map[string]generator.RouteInfo {
 	for path, routeInfo := range g.routesHandlers {
 		routes[path] = routeInfo
 	}
+	for path, routeInfo := range routes {
+		routeInfo.Parameters.Responses = mergeResponses(routeInfo.Parameters.Responses, g.defaultResponses)
+		routes[path] = routeInfo
+	}
 	return routes
 }
+
+// mergeResponses adds default responses with statuses not declared by route
+func mergeResponses(responses []generator.ResponseParameters, defaults []generator.ResponseParameters) []generator.ResponseParameters {
+	res := make([]generator.ResponseParameters, 0, len(responses)+len(defaults))
+	res = append(res, responses...)
+	for _, defResp := range defaults {
+		var found bool
+		for _, resp := range responses {
+			if resp.Status == defResp.Status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, defResp)
+		}
+	}
+	return res
+}
@@ -0,0 +1,82 @@
+package wrapper
+
+import (
+	"context"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDefaultResponses(t *testing.T) {
+	router := NewRouter(echo.New())
+	router.SetDefaultResponses(
+		generator.ResponseParameters{Status: http.StatusInternalServerError, Body: ProblemDetails{}},
+		generator.ResponseParameters{Status: http.StatusNotFound, Description: "router not found"},
+	)
+	group := router.Group("/users", "Users")
+	group.SetDefaultResponses(generator.ResponseParameters{Status: http.StatusNotFound, Description: "group not found"})
+	admin := group.Group("/admin", "Admin")
+	handler := func(ctx context.Context, req EmptyReq) (EmptyResp, error) {
+		return EmptyResp{}, nil
+	}
+	group.GET("/list", generator.HandlerParameters{}, handler)
+	admin.GET("/list", generator.HandlerParameters{
+		Responses: []generator.ResponseParameters{{Status: http.StatusNotFound, Description: "route not found"}},
+	}, handler)
+
+	routes := router.getRoutes()
+	descriptions := func(key string) map[int]string {
+		res := make(map[int]string)
+		for _, resp := range routes[key].Parameters.Responses {
+			res[resp.Status] = resp.Description
+		}
+		return res
+	}
+	assert.Equal(t, map[int]string{
+		http.StatusNotFound:            "group not found",
+		http.StatusInternalServerError: "",
+	}, descriptions("GET~/users/list"))
+	assert.Equal(t, map[int]string{
+		http.StatusNotFound:            "route not found",
+		http.StatusInternalServerError: "",
+	}, descriptions("GET~/users/admin/list"))
+}
+
+func TestSpecTags(t *testing.T) {
+	router := NewRouter(echo.New(), generator.SpecOptions{
+		Title: "Users API",
+		Tags: []generator.TagInfo{
+			{Name: "Admin"},
+			{Name: "Health", Description: "Service health"},
+		},
+	})
+	users := router.GroupWithTag("/users", generator.TagInfo{Name: "Users", Description: "Users management"})
+	users.GroupWithTag("/admin", generator.TagInfo{Name: "Admin", Description: "Administration"})
+	users.Group("/avatars", "Avatars")
+	router.Group("/health", "Health")
+
+	assert.Equal(t, []generator.TagInfo{
+		{Name: "Admin", Description: "Administration"},
+		{Name: "Health", Description: "Service health"},
+		{Name: "Users", Description: "Users management"},
+		{Name: "Avatars"},
+	}, router.specTags())
+}
+
+func TestGroupPUTMethod(t *testing.T) {
+	e := echo.New()
+	group := NewRouter(e).Group("/items", "Items")
+	group.PUT("/:id", generator.HandlerParameters{}, func(ctx context.Context, req struct{}) error {
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader("{}"))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serveTest(e, http.MethodPatch, "/items/1")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,311 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// customJSON reports if values of type are encoded by their own methods or documented by registered schema,
+// such values are not changed
+func customJSON(t reflect.Type, types *generator.TypeRegistry) bool {
+	if _, ok := types.Schema(t); ok {
+		return true
+	}
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	ptrType := reflect.PtrTo(t)
+	return ptrType.Implements(jsonMarshalerType) || ptrType.Implements(textMarshalerType) ||
+		ptrType.Implements(jsonUnmarshalerType) || ptrType.Implements(textUnmarshalerType)
+}
+
+// writeJSON writes JSON body, 64-bit integers are encoded as strings if router documents them so
+func (s *RouteWrapper) writeJSON(c echo.Context, status int, body interface{}) error {
+	if !s.int64AsString {
+		return c.JSON(status, body)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	data, err = quoteInt64(data, reflect.ValueOf(body), s.types)
+	if err != nil {
+		return err
+	}
+	return c.JSONBlob(status, data)
+}
+
+// requestJSON converts 64-bit integers encoded as strings in request JSON of type to numbers if router
+// documents them as strings
+func (s *RouteWrapper) requestJSON(data []byte, t reflect.Type) ([]byte, error) {
+	if !s.int64AsString {
+		return data, nil
+	}
+	return unquoteInt64(data, t, s.types)
+}
+
+// quoteInt64 replaces 64-bit integers of value JSON encoding by strings
+func quoteInt64(data []byte, val reflect.Value, types *generator.TypeRegistry) ([]byte, error) {
+	if !val.IsValid() || bytes.Equal(data, nullJSON) {
+		return data, nil
+	}
+	t := val.Type()
+	if _, _, ok := generator.OptionalElem(t); ok && val.CanInterface() {
+		value, present := val.Interface().(optionalValue).presentValue()
+		if !present {
+			return data, nil
+		}
+		return quoteInt64(data, reflect.ValueOf(value), types)
+	}
+	if types.Int64String(t) {
+		if data[0] == '"' {
+			// Encoded as string by ",string" option
+			return data, nil
+		}
+		return []byte(strconv.Quote(string(data))), nil
+	}
+	if customJSON(t, types) {
+		return data, nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return data, nil
+		}
+		return quoteInt64(data, val.Elem(), types)
+	case reflect.Slice, reflect.Array:
+		if data[0] != '[' {
+			// Byte slices are encoded as base64 strings
+			return data, nil
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		for i := range items {
+			item, err := quoteInt64(items[i], val.Index(i), types)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return json.Marshal(items)
+	case reflect.Map:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			key, err := mapKeyString(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			item, ok := items[key]
+			if !ok {
+				continue
+			}
+			if items[key], err = quoteInt64(item, iter.Value(), types); err != nil {
+				return nil, err
+			}
+		}
+		// Keys are sorted like by encoding/json
+		return json.Marshal(items)
+	case reflect.Struct:
+		return quoteStructInt64(data, val, types)
+	}
+	return data, nil
+}
+
+// quoteStructInt64 replaces 64-bit integers of struct fields, properties keep order of struct fields
+func quoteStructInt64(data []byte, val reflect.Value, types *generator.TypeRegistry) ([]byte, error) {
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString("{")
+	for _, field := range generator.JSONFields(val.Type()) {
+		fInfo := generator.GetFieldInfo(field)
+		if fInfo == nil {
+			continue
+		}
+		raw, ok := props[fInfo.JSONName]
+		if !ok {
+			// Omitted empty field or field of nil embedded struct
+			continue
+		}
+		fVal, err := val.FieldByIndexErr(field.Index)
+		if err != nil {
+			return nil, err
+		}
+		if raw, err = quoteInt64(raw, fVal, types); err != nil {
+			return nil, errors.Wrapf(err, "field %s", fInfo.JSONName)
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(fInfo.JSONName)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(raw)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// mapKeyString returns JSON property name of map key, like encoding/json does
+func mapKeyString(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", errors.Errorf("unsupported map key type %s", key.Type())
+}
+
+// unquoteInt64 replaces 64-bit integers encoded as strings in JSON of type by numbers, so encoding/json
+// can decode them. Variants of registered unions are chosen by discriminator property
+func unquoteInt64(data []byte, t reflect.Type, types *generator.TypeRegistry) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, nullJSON) {
+		return data, nil
+	}
+	if elem, _, ok := generator.OptionalElem(t); ok {
+		return unquoteInt64(data, elem, types)
+	}
+	if types.Int64String(t) {
+		if trimmed[0] != '"' {
+			return data, nil
+		}
+		str, err := strconv.Unquote(string(trimmed))
+		if err != nil {
+			return data, nil
+		}
+		if _, err := strconv.ParseFloat(str, 64); err != nil {
+			// Invalid value is reported by encoding/json
+			return data, nil
+		}
+		return []byte(str), nil
+	}
+	if customJSON(t, types) {
+		return data, nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return unquoteInt64(data, t.Elem(), types)
+	case reflect.Interface:
+		union, ok := types.Union(t)
+		if !ok {
+			return data, nil
+		}
+		var props map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &props); err != nil {
+			return data, nil
+		}
+		var value string
+		if err := json.Unmarshal(props[union.Discriminator], &value); err != nil {
+			return data, nil
+		}
+		variantType, ok := union.Variants[value]
+		if !ok {
+			// Unknown variant is reported by union decoder
+			return data, nil
+		}
+		return unquoteInt64(data, variantType, types)
+	case reflect.Slice, reflect.Array:
+		if trimmed[0] != '[' {
+			return data, nil
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return data, nil
+		}
+		for i := range items {
+			item, err := unquoteInt64(items[i], t.Elem(), types)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return json.Marshal(items)
+	case reflect.Map:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return data, nil
+		}
+		for key, item := range items {
+			item, err := unquoteInt64(item, t.Elem(), types)
+			if err != nil {
+				return nil, err
+			}
+			items[key] = item
+		}
+		return json.Marshal(items)
+	case reflect.Struct:
+		var props map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &props); err != nil {
+			return data, nil
+		}
+		fields := generator.JSONFields(t)
+		for key, raw := range props {
+			field, ok := propField(fields, key)
+			if !ok || generator.GetFieldInfo(field).AsString {
+				continue
+			}
+			raw, err := unquoteInt64(raw, field.Type, types)
+			if err != nil {
+				return nil, err
+			}
+			props[key] = raw
+		}
+		return json.Marshal(props)
+	}
+	return data, nil
+}
+
+// propField returns struct field decoded from JSON property like encoding/json chooses it: field with exactly
+// matching name, otherwise the first field with name matching case-insensitively
+func propField(fields []reflect.StructField, key string) (reflect.StructField, bool) {
+	folded := -1
+	for i, field := range fields {
+		fInfo := generator.GetFieldInfo(field)
+		if fInfo == nil {
+			continue
+		}
+		if fInfo.JSONName == key {
+			return field, true
+		}
+		if folded < 0 && strings.EqualFold(fInfo.JSONName, key) {
+			folded = i
+		}
+	}
+	if folded < 0 {
+		return reflect.StructField{}, false
+	}
+	return fields[folded], true
+}
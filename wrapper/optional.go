@@ -0,0 +1,153 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Optional is request field which can be absent, like field of partial update. Set reports if field
+// was present in request body or parameters. null is rejected, Nullable fields accept it
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
+
+// Some returns optional with value set
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Set: true}
+}
+
+// OptionalType implements generator.OptionalValue, field is documented by schema of T
+func (o Optional[T]) OptionalType() (reflect.Type, bool) {
+	return reflect.TypeOf((*T)(nil)).Elem(), false
+}
+
+// UnmarshalJSON is called only for present fields
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), nullJSON) {
+		return errors.New("null is not allowed")
+	}
+	err := json.Unmarshal(data, &o.Value)
+	if err != nil {
+		return err
+	}
+	o.Set = true
+	return nil
+}
+
+// MarshalJSON encodes absent value as null
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set {
+		return nullJSON, nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// UnmarshalText sets value from path, query, header or cookie parameter
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	err := setParamValue(reflect.ValueOf(&o.Value).Elem(), string(text))
+	if err != nil {
+		return err
+	}
+	o.Set = true
+	return nil
+}
+
+func (o Optional[T]) presentValue() (interface{}, bool) {
+	return o.Value, o.Set
+}
+
+// Nullable is request field which can be absent, set to null or set to value, like field of JSON Merge Patch
+// where null clears value. Set reports if field was present, Null reports if it was null
+type Nullable[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+// NullableOf returns nullable with value set
+func NullableOf[T any](value T) Nullable[T] {
+	return Nullable[T]{Value: value, Set: true}
+}
+
+// Null returns nullable set to null
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Set: true, Null: true}
+}
+
+// OptionalType implements generator.OptionalValue, field is documented by nullable schema of T
+func (n Nullable[T]) OptionalType() (reflect.Type, bool) {
+	return reflect.TypeOf((*T)(nil)).Elem(), true
+}
+
+// UnmarshalJSON is called only for present fields
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	var value T
+	n.Set = true
+	n.Null = bytes.Equal(bytes.TrimSpace(data), nullJSON)
+	if !n.Null {
+		err := json.Unmarshal(data, &value)
+		if err != nil {
+			return err
+		}
+	}
+	n.Value = value
+	return nil
+}
+
+// MarshalJSON encodes absent and null values as null
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.Set || n.Null {
+		return nullJSON, nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// UnmarshalText sets value from path, query, header or cookie parameter
+func (n *Nullable[T]) UnmarshalText(text []byte) error {
+	var value T
+	err := setParamValue(reflect.ValueOf(&value).Elem(), string(text))
+	if err != nil {
+		return err
+	}
+	*n = NullableOf(value)
+	return nil
+}
+
+func (n Nullable[T]) presentValue() (interface{}, bool) {
+	return n.Value, n.Set && !n.Null
+}
+
+// optionalValue is implemented by Optional and Nullable, returns value if it is present and not null
+type optionalValue interface {
+	presentValue() (interface{}, bool)
+}
+
+// checkResponseOptionals rejects Optional fields of response type. Absent Optional is encoded as null, but it is
+// documented by schema which does not accept null, responses should use Nullable or pointer fields
+func checkResponseOptionals(t reflect.Type, visited map[reflect.Type]bool) error {
+	if visited[t] {
+		return nil
+	}
+	visited[t] = true
+	if elem, nullable, ok := generator.OptionalElem(t); ok {
+		if !nullable {
+			return errors.Errorf("%s is not allowed in response, use Nullable", t.String())
+		}
+		return checkResponseOptionals(elem, visited)
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return checkResponseOptionals(t.Elem(), visited)
+	case reflect.Struct:
+		for _, field := range generator.JSONFields(t) {
+			if err := checkResponseOptionals(field.Type, visited); err != nil {
+				return errors.Wrapf(err, "field %s", field.Name)
+			}
+		}
+	}
+	return nil
+}
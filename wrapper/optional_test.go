@@ -0,0 +1,146 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type patchAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type profilePatchReq struct {
+	ID       int                    `param:"id,path"`
+	Version  Optional[int]          `param:"version,query"`
+	Name     Optional[string]       `json:"name" validate:"min=3"`
+	Nickname Nullable[string]       `json:"nickname"`
+	Note     Nullable[string]       `json:"note" validate:"min=2"`
+	Address  Nullable[patchAddress] `json:"address"`
+	Plan     Optional[planTier]     `json:"plan"`
+}
+
+type titlePatchReq struct {
+	Title Optional[string] `json:"title" validate:"required,min=3"`
+	Tags  []Optional[int]  `json:"tags" validate:"dive,required"`
+}
+
+func TestCallProcessorOptionalFields(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	router.SetEnumValidation(true)
+	docs := generator.NewDocComments()
+	err := docs.AddPackage("github.com/AlhimicMan/goswag/wrapper", "validation_test.go")
+	if !assert.NoError(t, err) {
+		return
+	}
+	router.SetDocComments(docs)
+	group := router.Group("/profiles", "Profiles")
+	var got profilePatchReq
+	group.PATCH("/:id", generator.HandlerParameters{}, func(ctx context.Context, req profilePatchReq) (EmptyResp, error) {
+		got = req
+		return EmptyResp{}, nil
+	})
+	patch := func(target string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, generator.MergePatchContentType)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := patch("/profiles/7?version=3", `{"nickname": null, "address": {"city": "Oslo"}, "plan": "pro"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 7, got.ID)
+	assert.Equal(t, Some(3), got.Version)
+	assert.False(t, got.Name.Set)
+	assert.Equal(t, Null[string](), got.Nickname)
+	assert.Equal(t, NullableOf(patchAddress{City: "Oslo"}), got.Address)
+	assert.Equal(t, Some(planPro), got.Plan)
+
+	rec = patch("/profiles/7", `{"name": "Bob", "nickname": "bobby"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, got.Version.Set)
+	assert.Equal(t, Some("Bob"), got.Name)
+	assert.Equal(t, NullableOf("bobby"), got.Nickname)
+	assert.False(t, got.Address.Set)
+
+	rec = patch("/profiles/7", `{}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, got.Note.Set)
+
+	rec = patch("/profiles/7", `{"note": null}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, Null[string](), got.Note)
+
+	rec = patch("/profiles/7", `{"name": null}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = patch("/profiles/7", `{"name": "Bo", "address": {}, "plan": "gold"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	res := ProblemDetails{}
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	assert.NoError(t, err)
+	rules := make(map[string]string)
+	for _, fErr := range res.Errors {
+		rules[fErr.Field] = fErr.Rule
+	}
+	assert.Equal(t, map[string]string{"name": "min", "address.city": "required"}, rules)
+
+	rec = patch("/profiles/7", `{"plan": "gold"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"rule":"enum"`)
+
+	group.PATCH("/:id/title", generator.HandlerParameters{}, func(ctx context.Context, req titlePatchReq) (EmptyResp, error) {
+		return EmptyResp{}, nil
+	})
+	rec = patch("/profiles/7/title", `{"title": "News"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = patch("/profiles/7/title", `{"tags": [1]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	res = ProblemDetails{}
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	assert.NoError(t, err)
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, "title", res.Errors[0].Field)
+		assert.Equal(t, "required", res.Errors[0].Rule)
+	}
+}
+
+func TestOptionalResponseRejected(t *testing.T) {
+	type profileResp struct {
+		Name     Optional[string] `json:"name"`
+		Nickname Nullable[string] `json:"nickname"`
+	}
+	type nullableResp struct {
+		Nickname Nullable[string] `json:"nickname"`
+	}
+	router := NewRouter(echo.New())
+	group := router.Group("/profiles", "Profiles")
+	assert.Panics(t, func() {
+		group.GET("/:id", generator.HandlerParameters{}, func(ctx context.Context, req EmptyReq) (profileResp, error) {
+			return profileResp{}, nil
+		})
+	})
+	assert.NotPanics(t, func() {
+		group.GET("/:id", generator.HandlerParameters{}, func(ctx context.Context, req EmptyReq) (nullableResp, error) {
+			return nullableResp{}, nil
+		})
+	})
+}
+
+func TestOptionalJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Name     Optional[string] `json:"name"`
+		Age      Optional[int]    `json:"age"`
+		Nickname Nullable[string] `json:"nickname"`
+	}{Name: Some("Bob"), Nickname: Null[string]()})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name": "Bob", "age": null, "nickname": null}`, string(data))
+}
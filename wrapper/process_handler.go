@@ -6,6 +6,7 @@ import (
 	"github.com/pkg/errors"
 	"net/http"
 	"reflect"
+	"runtime"
 )
 
 func processHandler(handler interface{}) (generator.HandlerInfo, error) {
@@ -57,15 +58,20 @@ func processHandler(handler interface{}) (generator.HandlerInfo, error) {
 		if !handlerType.Out(1).Implements(errorInterface) {
 			panic("Second return value should be an error")
 		}
-		outRes := handlerType.Out(0)
+		outRes := responseBodyType(handlerType.Out(0))
+		if err := checkResponseOptionals(outRes, make(map[reflect.Type]bool)); err != nil {
+			panic(errors.Wrap(err, "First return value is not valid response").Error())
+		}
 		outType = &outRes
 	} else {
 		return generator.HandlerInfo{}, errors.Errorf("cannot register handler: unsupported out params count %d", outParamsCount)
 	}
 
 	handlerInfo := generator.HandlerInfo{
-		RequestType: &reqParam,
-		OutputType:  outType,
+		FuncName:            runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name(),
+		RequestType:         &reqParam,
+		OutputType:          outType,
+		ValidationErrorType: &validationErrorType,
 	}
 	return handlerInfo, nil
 }
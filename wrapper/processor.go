@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/AlhimicMan/goswag/generator"
 	"github.com/labstack/echo/v4"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"reflect"
@@ -12,19 +13,37 @@ import (
 )
 
 type ReqField struct {
-	ParamName       string
-	StructFieldName string
+	ParamName        string
+	StructFieldName  string
+	CollectionFormat string
+	// FieldIndex is index path of field, fields promoted from embedded structs have several indexes
+	FieldIndex []int
+}
+
+// reqParams holds request struct fields bound from path, query, headers and cookies.
+// objects are structs and maps bound from bracketed query keys
+type reqParams struct {
+	path    []ReqField
+	query   []ReqField
+	objects []ReqField
+	header  []ReqField
+	cookie  []ReqField
+	// unions decodes body with fields of registered union interfaces, nil if body has no such fields
+	unions *unionDecoder
 }
 
 type fileField struct {
 	fieldName       string
 	structFieldName string
+	fieldIndex      []int
 	multiple        bool
 }
 
 var fHeaderType = reflect.TypeOf(&multipart.FileHeader{})
 
-func (g *WrapGroup) callProcessor(path string, handler interface{}, processBody bool) echo.HandlerFunc {
+// callProcessor creates echo handler calling wrapped handler. successStatus is response status
+// unless handler result defines it, by default 200 for handlers with result and 204 for error-only handlers
+func (g *WrapGroup) callProcessor(path string, handler interface{}, processBody bool, successStatus int) echo.HandlerFunc {
 	handlerType := reflect.TypeOf(handler)
 	inParamsCount := handlerType.NumIn()
 	outParamsCount := handlerType.NumOut()
@@ -35,41 +54,24 @@ func (g *WrapGroup) callProcessor(path string, handler interface{}, processBody
 		pName := param[1:]
 		pathParamNames = append(pathParamNames, pName)
 	}
-	queryParams, pathParams := g.getParams(reqParam, pathParamNames, processBody)
+	params := g.getParams(reqParam, pathParamNames, processBody)
+	g.routeWrapper.addOptionalTypes(reqParam)
 	fParams := g.getUploadFileParams(reqParam)
 	handlerFunc := reflect.ValueOf(handler)
 	return func(c echo.Context) error {
-		inputVal := reflect.New(reqParam)
-		if processBody {
-			if len(fParams) == 0 {
-				inputValPtr := inputVal.Interface()
-				err := json.NewDecoder(c.Request().Body).Decode(inputValPtr)
-				if err != nil {
-					return fmt.Errorf("could not decode req body json: %w", err)
-				}
-				inputVal = reflect.ValueOf(inputValPtr)
-			} else {
-				mForm, err := c.MultipartForm()
-				if err != nil {
-					return fmt.Errorf("cannot get multipart form: %w", err)
-				}
-				err = g.processMultipartUpload(mForm, inputVal, fParams)
-				if err != nil {
-					return fmt.Errorf("cannot process multipart form: %w", err)
-				}
-			}
-
+		inputVal, err := g.bindRequest(c, reqParam, params, fParams, processBody)
+		if err != nil {
+			return g.routeWrapper.writeError(c, err)
 		}
-		inputVal = inputVal.Elem()
-		for _, pParamName := range pathParams {
-			paramVal := c.Param(pParamName.ParamName)
-			fItem := inputVal.FieldByName(pParamName.StructFieldName)
-			fItem.Set(reflect.ValueOf(paramVal))
+		err = g.routeWrapper.validateRequest(inputVal)
+		if err != nil {
+			return g.routeWrapper.writeError(c, err)
 		}
-		for _, qParamName := range queryParams {
-			paramVal := c.QueryParam(qParamName.ParamName)
-			fItem := inputVal.FieldByName(qParamName.StructFieldName)
-			fItem.Set(reflect.ValueOf(paramVal))
+		if g.routeWrapper.enumValidation {
+			err = validateEnums(inputVal, g.routeWrapper.docs)
+			if err != nil {
+				return g.routeWrapper.writeError(c, err)
+			}
 		}
 
 		inValues := make([]reflect.Value, 0)
@@ -88,9 +90,10 @@ func (g *WrapGroup) callProcessor(path string, handler interface{}, processBody
 		} else {
 			errVal = results[1]
 		}
-		errInterface := errVal.Interface()
 		var resultErr error = nil
-		if errInterface != nil {
+		// Handler can return typed nil pointer like *ErrorResult, it is not an error
+		if !isNilValue(errVal) {
+			errInterface := errVal.Interface()
 			var ok bool
 			resultErr, ok = errInterface.(error)
 			if !ok {
@@ -99,21 +102,124 @@ func (g *WrapGroup) callProcessor(path string, handler interface{}, processBody
 		}
 
 		if resultErr != nil {
-			return c.JSON(http.StatusInternalServerError, resultErr)
+			return g.routeWrapper.writeError(c, resultErr)
 		}
 
 		if outParamsCount == 2 {
-			output := results[0].Interface()
-			return c.JSON(http.StatusOK, output)
+			status := successStatus
+			if status == 0 {
+				status = http.StatusOK
+			}
+			return g.routeWrapper.writeResult(c, status, results[0].Interface())
+		}
+		if c.Response().Committed {
+			// Handler wrote response itself
+			return nil
+		}
+		if successStatus == 0 {
+			return c.NoContent(http.StatusNoContent)
+		}
+		return c.NoContent(successStatus)
+	}
+}
+
+// bindRequest creates request struct value from body, path, query, headers and cookies
+func (g *WrapGroup) bindRequest(c echo.Context, reqParam reflect.Type, params reqParams, fParams []fileField, processBody bool) (reflect.Value, error) {
+	inputVal := reflect.New(reqParam)
+	if processBody {
+		if len(fParams) == 0 {
+			inputValPtr := inputVal.Interface()
+			var err error
+			if params.unions != nil || g.routeWrapper.int64AsString {
+				var body []byte
+				body, err = io.ReadAll(c.Request().Body)
+				if err == nil {
+					body, err = g.routeWrapper.requestJSON(body, reqParam)
+				}
+				if err == nil {
+					// Nil decoder decodes body by encoding/json
+					err = params.unions.decode(body, inputVal.Elem())
+				}
+			} else {
+				err = json.NewDecoder(c.Request().Body).Decode(inputValPtr)
+			}
+			if err != nil {
+				return reflect.Value{}, ErrorResult{
+					Status:  http.StatusBadRequest,
+					Code:    "invalid_body",
+					Message: fmt.Sprintf("could not decode req body json: %v", err),
+				}
+			}
+			inputVal = reflect.ValueOf(inputValPtr)
+		} else {
+			mForm, err := c.MultipartForm()
+			if err != nil {
+				return reflect.Value{}, ErrorResult{
+					Status:  http.StatusBadRequest,
+					Code:    "invalid_body",
+					Message: fmt.Sprintf("cannot get multipart form: %v", err),
+				}
+			}
+			err = g.processMultipartUpload(mForm, inputVal, fParams, params.unions)
+			if err != nil {
+				return reflect.Value{}, ErrorResult{
+					Status:  http.StatusBadRequest,
+					Code:    "invalid_body",
+					Message: fmt.Sprintf("cannot process multipart form: %v", err),
+				}
+			}
 		}
-		return nil
 	}
+	inputVal = inputVal.Elem()
+	for _, pParamName := range params.path {
+		err := bindParam(inputVal, pParamName, []string{c.Param(pParamName.ParamName)})
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	queryValues := c.QueryParams()
+	for _, qParamName := range params.query {
+		err := bindParam(inputVal, qParamName, queryValues[qParamName.ParamName])
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	for _, oParamName := range params.objects {
+		err := bindObjectParam(inputVal, oParamName, queryValues, g.routeWrapper.types)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	for _, hParamName := range params.header {
+		err := bindParam(inputVal, hParamName, c.Request().Header.Values(hParamName.ParamName))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	for _, cParamName := range params.cookie {
+		cookie, err := c.Cookie(cParamName.ParamName)
+		if err != nil {
+			continue
+		}
+		err = bindParam(inputVal, cParamName, []string{cookie.Value})
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return inputVal, nil
+}
+
+func isNilValue(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return val.IsNil()
+	}
+	return false
 }
 
 func (g *WrapGroup) getUploadFileParams(paramType reflect.Type) []fileField {
 	fParams := make([]fileField, 0)
-	for i := 0; i < paramType.NumField(); i++ {
-		field := paramType.Field(i)
+	for _, field := range generator.JSONFields(paramType) {
 		if field.Type == fHeaderType {
 			fInfo := generator.GetFieldInfo(field)
 			if fInfo == nil {
@@ -122,6 +228,7 @@ func (g *WrapGroup) getUploadFileParams(paramType reflect.Type) []fileField {
 			fP := fileField{
 				fieldName:       fInfo.Name,
 				structFieldName: field.Name,
+				fieldIndex:      field.Index,
 				multiple:        false,
 			}
 			fParams = append(fParams, fP)
@@ -136,6 +243,7 @@ func (g *WrapGroup) getUploadFileParams(paramType reflect.Type) []fileField {
 				fP := fileField{
 					fieldName:       fInfo.Name,
 					structFieldName: field.Name,
+					fieldIndex:      field.Index,
 					multiple:        true,
 				}
 				fParams = append(fParams, fP)
@@ -145,16 +253,16 @@ func (g *WrapGroup) getUploadFileParams(paramType reflect.Type) []fileField {
 	return fParams
 }
 
-func (g *WrapGroup) processMultipartUpload(mForm *multipart.Form, inputVal reflect.Value, fParams []fileField) error {
+func (g *WrapGroup) processMultipartUpload(mForm *multipart.Form, inputVal reflect.Value, fParams []fileField, unions *unionDecoder) error {
 	reqBody := mForm.Value["request"]
 	if len(reqBody) > 0 {
-		reqVal := []byte(reqBody[0])
-		inputValPtr := inputVal.Interface()
-		err := json.Unmarshal(reqVal, inputValPtr)
+		reqVal, err := g.routeWrapper.requestJSON([]byte(reqBody[0]), inputVal.Elem().Type())
+		if err == nil {
+			err = unions.decode(reqVal, inputVal.Elem())
+		}
 		if err != nil {
 			return fmt.Errorf("could not decode req body json: %w", err)
 		}
-		inputVal = reflect.ValueOf(inputValPtr)
 	}
 	inputVal = inputVal.Elem()
 	for _, fP := range fParams {
@@ -162,7 +270,10 @@ func (g *WrapGroup) processMultipartUpload(mForm *multipart.Form, inputVal refle
 		if !ok {
 			continue
 		}
-		fItem := inputVal.FieldByName(fP.structFieldName)
+		fItem, err := fieldByIndex(inputVal, fP.fieldIndex)
+		if err != nil {
+			return err
+		}
 		if fP.multiple {
 			fItem.Set(reflect.ValueOf(fHeaders))
 		} else {
@@ -175,15 +286,18 @@ func (g *WrapGroup) processMultipartUpload(mForm *multipart.Form, inputVal refle
 	return nil
 }
 
-func (g *WrapGroup) getParams(paramType reflect.Type, pathParams []string, processBody bool) (qParamNames []ReqField, pParamNames []ReqField) {
-	for i := 0; i < paramType.NumField(); i++ {
-		field := paramType.Field(i)
+func (g *WrapGroup) getParams(paramType reflect.Type, pathParams []string, processBody bool) reqParams {
+	var params reqParams
+	if processBody {
+		params.unions = newUnionDecoder(paramType, g.routeWrapper.types)
+	}
+	for _, field := range generator.JSONFields(paramType) {
 		fInfo := generator.GetFieldInfo(field)
 		if fInfo == nil {
 			continue
 		}
-		if field.Type.Kind() != reflect.String {
-			// Only type string supported for query parameters
+		paramKind := g.routeWrapper.types.ParamKind(field.Type)
+		if paramKind == generator.UnsupportedParam {
 			continue
 		}
 		var isPathParam bool
@@ -197,14 +311,27 @@ func (g *WrapGroup) getParams(paramType reflect.Type, pathParams []string, proce
 			}
 		}
 		reqField := ReqField{
-			ParamName:       fInfo.Name,
-			StructFieldName: field.Name,
+			ParamName:        fInfo.Name,
+			StructFieldName:  field.Name,
+			CollectionFormat: fInfo.CollectionFormat,
+			FieldIndex:       field.Index,
+		}
+		if paramKind == generator.ObjectParam {
+			// Objects can be passed only in query string
+			if !isPathParam && (fInfo.In == "query" || (fInfo.In == "" && !processBody)) {
+				params.objects = append(params.objects, reqField)
+			}
+			continue
 		}
-		if isPathParam {
-			pParamNames = append(pParamNames, reqField)
+		if fInfo.In == "header" {
+			params.header = append(params.header, reqField)
+		} else if fInfo.In == "cookie" {
+			params.cookie = append(params.cookie, reqField)
+		} else if isPathParam {
+			params.path = append(params.path, reqField)
 		} else if fInfo.In == "query" || !processBody {
-			qParamNames = append(qParamNames, reqField)
+			params.query = append(params.query, reqField)
 		}
 	}
-	return qParamNames, pParamNames
+	return params
 }
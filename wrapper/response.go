@@ -0,0 +1,84 @@
+package wrapper
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"reflect"
+)
+
+// ResponseHeaders is implemented by handler results setting response headers
+type ResponseHeaders interface {
+	ResponseHeaders() http.Header
+}
+
+// BodyResponse is implemented by handler results wrapping response body
+type BodyResponse interface {
+	ResponseBody() interface{}
+}
+
+// Response is handler result choosing response status and headers dynamically. Only Body is sent to client.
+// Zero Status means route success status
+type Response[T any] struct {
+	Status  int
+	Headers http.Header
+	Body    T
+}
+
+func (r Response[T]) StatusCode() int {
+	return r.Status
+}
+
+func (r Response[T]) ResponseHeaders() http.Header {
+	return r.Headers
+}
+
+func (r Response[T]) ResponseBody() interface{} {
+	return r.Body
+}
+
+// bodyType returns type of body sent to client
+func (r Response[T]) bodyType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// typedBodyResponse is implemented by handler results which body type is known before handler call, like Response[T]
+type typedBodyResponse interface {
+	bodyType() reflect.Type
+}
+
+var typedBodyResponseType = reflect.TypeOf((*typedBodyResponse)(nil)).Elem()
+
+// responseBodyType returns type of body sent for handler result type. For Response[T] it is T
+func responseBodyType(outType reflect.Type) reflect.Type {
+	if !outType.Implements(typedBodyResponseType) {
+		return outType
+	}
+	result := reflect.New(outType).Elem()
+	if outType.Kind() == reflect.Ptr {
+		result = reflect.New(outType.Elem())
+	}
+	return result.Interface().(typedBodyResponse).bodyType()
+}
+
+// writeResult writes handler result. Status and headers can be defined by result with StatusCoder and
+// ResponseHeaders interfaces
+func (s *RouteWrapper) writeResult(c echo.Context, status int, output interface{}) error {
+	if coder, ok := output.(StatusCoder); ok && coder.StatusCode() != 0 {
+		status = coder.StatusCode()
+	}
+	if headers, ok := output.(ResponseHeaders); ok {
+		for name, values := range headers.ResponseHeaders() {
+			for _, value := range values {
+				c.Response().Header().Add(name, value)
+			}
+		}
+	}
+	body := output
+	if bodyResp, ok := output.(BodyResponse); ok {
+		body = bodyResp.ResponseBody()
+	}
+	if status == http.StatusNoContent || status == http.StatusNotModified || body == nil {
+		return c.NoContent(status)
+	}
+	return s.writeJSON(c, status, body)
+}
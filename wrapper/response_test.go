@@ -0,0 +1,143 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	openapi "github.com/go-openapi/spec"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type createdRes struct {
+	ID string `json:"id"`
+}
+
+func TestSuccessStatus(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/items", "Items")
+	group.GET("/created", generator.HandlerParameters{SuccessStatus: http.StatusCreated},
+		func(ctx context.Context, req EmptyReq) (createdRes, error) {
+			return createdRes{ID: "1"}, nil
+		})
+	group.GET("/deleted", generator.HandlerParameters{},
+		func(ctx context.Context, req EmptyReq) error {
+			return nil
+		})
+	group.GET("/accepted", generator.HandlerParameters{SuccessStatus: http.StatusAccepted},
+		func(ctx context.Context, req EmptyReq) error {
+			return nil
+		})
+	group.GET("/written", generator.HandlerParameters{},
+		func(ctx context.Context, req EmptyReq, _ *http.Request, w http.ResponseWriter) error {
+			_, err := w.Write([]byte("data"))
+			return err
+		})
+
+	rec := serveTest(e, http.MethodGet, "/items/created")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"id": "1"}`, rec.Body.String())
+
+	rec = serveTest(e, http.MethodGet, "/items/deleted")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = serveTest(e, http.MethodGet, "/items/accepted")
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	rec = serveTest(e, http.MethodGet, "/items/written")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "data", rec.Body.String())
+}
+
+type upsertReq struct {
+	Exists bool `param:"exists,query"`
+}
+
+func upsertHandler(ctx context.Context, req upsertReq) (Response[createdRes], error) {
+	if req.Exists {
+		return Response[createdRes]{Body: createdRes{ID: "1"}}, nil
+	}
+	headers := http.Header{}
+	headers.Set("Location", "/items/2")
+	return Response[createdRes]{Status: http.StatusCreated, Headers: headers, Body: createdRes{ID: "2"}}, nil
+}
+
+func TestDynamicResponse(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/items", "Items")
+	group.GET("", generator.HandlerParameters{}, upsertHandler)
+
+	rec := serveTest(e, http.MethodGet, "/items?exists=true")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "1"}`, rec.Body.String())
+
+	rec = serveTest(e, http.MethodGet, "/items")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/items/2", rec.Header().Get("Location"))
+	res := createdRes{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, "2", res.ID)
+
+	handlerInfo, err := processHandler(upsertHandler)
+	assert.NoError(t, err)
+	assert.Equal(t, reflect.TypeOf(createdRes{}), *handlerInfo.OutputType)
+
+	assert.Equal(t, reflect.TypeOf([]createdRes{}), responseBodyType(reflect.TypeOf(&Response[[]createdRes]{})))
+	assert.Equal(t, reflect.TypeOf(createdRes{}), responseBodyType(reflect.TypeOf(createdRes{})))
+}
+
+type counterRec struct {
+	ID      int64            `json:"id"`
+	Count   int32            `json:"count"`
+	Parts   []uint64         `json:"parts"`
+	Parent  *int64           `json:"parent"`
+	Limit   Nullable[int64]  `json:"limit"`
+	Timeout time.Duration    `json:"timeout"`
+	ByName  map[string]int64 `json:"by_name"`
+	Version int64            `json:"version,string"`
+	Hidden  int64            `json:"-"`
+}
+
+func TestInt64AsString(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	router.SetInt64AsString(true)
+	group := router.Group("/counters", "Counters")
+	var got counterRec
+	group.POST("", generator.HandlerParameters{}, func(ctx context.Context, req counterRec) (counterRec, error) {
+		got = req
+		return req, nil
+	})
+
+	body := `{"id": "9007199254740993", "count": 2, "parts": ["1", 2], "parent": "3", "limit": "4",
+		"timeout": 5, "by_name": {"a": "6"}, "version": "7"}`
+	req := httptest.NewRequest(http.MethodPost, "/counters", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	parent := int64(3)
+	expected := counterRec{ID: 9007199254740993, Count: 2, Parts: []uint64{1, 2}, Parent: &parent,
+		Limit: NullableOf(int64(4)), Timeout: 5, ByName: map[string]int64{"a": 6}, Version: 7}
+	assert.Equal(t, expected, got)
+	assert.Equal(t, `{"id":"9007199254740993","count":2,"parts":["1","2"],"parent":"3","limit":"4",`+
+		`"timeout":"5","by_name":{"a":"6"},"version":"7"}`, strings.TrimSpace(rec.Body.String()))
+
+	spec, err := router.ExportSpec("json")
+	assert.NoError(t, err)
+	var sw openapi.Swagger
+	assert.NoError(t, json.Unmarshal(spec, &sw))
+	props := sw.Definitions["wrapper.counterRec"].Properties
+	assert.Equal(t, openapi.StringOrArray{"string"}, props["id"].Type)
+	assert.Equal(t, openapi.StringOrArray{"integer"}, props["count"].Type)
+	assert.Equal(t, openapi.StringOrArray{"string"}, props["parts"].Items.Schema.Type)
+	assert.Equal(t, openapi.StringOrArray{"string"}, props["timeout"].Type)
+}
@@ -0,0 +1,238 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var nullJSON = []byte("null")
+
+// unionDecoder decodes request bodies with fields of registered union interfaces. Types containing unions
+// are found once per route, values of other types are decoded by encoding/json. Methods of nil decoder
+// use encoding/json only
+type unionDecoder struct {
+	types *generator.TypeRegistry
+	// unions holds types which values contain union interfaces
+	unions map[reflect.Type]bool
+}
+
+// newUnionDecoder returns decoder of values of type, nil if type does not contain union interfaces
+func newUnionDecoder(t reflect.Type, types *generator.TypeRegistry) *unionDecoder {
+	edges := make(map[reflect.Type][]reflect.Type)
+	collectTypeEdges(t, types, edges)
+	unions := make(map[reflect.Type]bool)
+	for typ := range edges {
+		if _, ok := types.Union(typ); ok {
+			unions[typ] = true
+		}
+	}
+	if len(unions) == 0 {
+		return nil
+	}
+	// Types referring union containing types contain unions too, recursive types are resolved by iterations
+	for changed := true; changed; {
+		changed = false
+		for typ, elems := range edges {
+			if unions[typ] {
+				continue
+			}
+			for _, elem := range elems {
+				if unions[elem] {
+					unions[typ] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return &unionDecoder{types: types, unions: unions}
+}
+
+// collectTypeEdges adds types which values are decoded as parts of values of type. Union interfaces
+// refer their variants
+func collectTypeEdges(t reflect.Type, types *generator.TypeRegistry, edges map[reflect.Type][]reflect.Type) {
+	if _, ok := edges[t]; ok {
+		return
+	}
+	edges[t] = nil
+	var elems []reflect.Type
+	if _, _, ok := generator.OptionalElem(t); ok {
+		// Optional values are decoded by their UnmarshalJSON
+		return
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		if union, ok := types.Union(t); ok {
+			for _, value := range union.Values() {
+				elems = append(elems, union.Variants[value])
+			}
+		}
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		elems = append(elems, t.Elem())
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			elems = append(elems, t.Elem())
+		}
+	case reflect.Struct:
+		for _, field := range generator.JSONFields(t) {
+			elems = append(elems, field.Type)
+		}
+	}
+	edges[t] = elems
+	for _, elem := range elems {
+		collectTypeEdges(elem, types, edges)
+	}
+}
+
+// decode decodes JSON to value, interface fields of registered unions are decoded to variant types
+// chosen by discriminator property. Values without unions are decoded by encoding/json
+func (d *unionDecoder) decode(data []byte, val reflect.Value) error {
+	if d == nil || !d.unions[val.Type()] {
+		return json.Unmarshal(data, val.Addr().Interface())
+	}
+	if bytes.Equal(bytes.TrimSpace(data), nullJSON) {
+		return nil
+	}
+	switch val.Kind() {
+	case reflect.Interface:
+		union, _ := d.types.Union(val.Type())
+		return d.decodeVariant(data, val, union)
+	case reflect.Ptr:
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		return d.decode(data, val.Elem())
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		if val.Kind() == reflect.Slice {
+			val.Set(reflect.MakeSlice(val.Type(), len(items), len(items)))
+		}
+		for i, item := range items {
+			if i >= val.Len() {
+				break
+			}
+			if err := d.decode(item, val.Index(i)); err != nil {
+				return errors.Wrapf(err, "[%d]", i)
+			}
+		}
+		return nil
+	case reflect.Map:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		if val.IsNil() {
+			val.Set(reflect.MakeMapWithSize(val.Type(), len(items)))
+		}
+		for key, item := range items {
+			itemVal := reflect.New(val.Type().Elem()).Elem()
+			if err := d.decode(item, itemVal); err != nil {
+				return errors.Wrapf(err, "[%s]", key)
+			}
+			val.SetMapIndex(reflect.ValueOf(key).Convert(val.Type().Key()), itemVal)
+		}
+		return nil
+	case reflect.Struct:
+		return d.decodeStruct(data, val)
+	}
+	return json.Unmarshal(data, val.Addr().Interface())
+}
+
+// decodeVariant decodes JSON object to union variant chosen by discriminator property
+func (d *unionDecoder) decodeVariant(data []byte, val reflect.Value, union *generator.Union) error {
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(data, &props); err != nil {
+		return err
+	}
+	rawValue, ok := props[union.Discriminator]
+	if !ok {
+		return errors.Errorf("missing discriminator property %s", union.Discriminator)
+	}
+	var value string
+	if err := json.Unmarshal(rawValue, &value); err != nil {
+		return errors.Wrapf(err, "invalid discriminator property %s", union.Discriminator)
+	}
+	variantType, ok := union.Variants[value]
+	if !ok {
+		return errors.Errorf("unknown %s %q, expected one of: %s", union.Discriminator, value, strings.Join(union.Values(), ", "))
+	}
+	variantVal := reflect.New(variantType).Elem()
+	if err := d.decode(data, variantVal); err != nil {
+		return err
+	}
+	val.Set(variantVal)
+	return nil
+}
+
+// decodeStruct decodes JSON object fields matching struct fields names, like encoding/json.
+// Unknown properties are ignored
+func (d *unionDecoder) decodeStruct(data []byte, val reflect.Value) error {
+	props, err := objectProps(data)
+	if err != nil {
+		return err
+	}
+	fields := generator.JSONFields(val.Type())
+	// Properties are decoded in document order to fields chosen like by encoding/json,
+	// so later duplicate keys overwrite earlier ones
+	for _, prop := range props {
+		field, ok := propField(fields, prop.key)
+		if !ok {
+			continue
+		}
+		fInfo := generator.GetFieldInfo(field)
+		fVal, err := fieldByIndex(val, field.Index)
+		if err != nil {
+			return err
+		}
+		raw := prop.value
+		if fInfo.AsString && fVal.Kind() != reflect.String {
+			// Value encoded as JSON string by ",string" option
+			if str, err := strconv.Unquote(string(raw)); err == nil {
+				raw = json.RawMessage(str)
+			}
+		}
+		if err := d.decode(raw, fVal); err != nil {
+			return errors.Wrapf(err, "field %s", fInfo.JSONName)
+		}
+	}
+	return nil
+}
+
+// objectProp is property of JSON object
+type objectProp struct {
+	key   string
+	value json.RawMessage
+}
+
+// objectProps returns properties of JSON object in document order
+func objectProps(data []byte) ([]objectProp, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, errors.Errorf("expected JSON object, got %v", token)
+	}
+	props := make([]objectProp, 0)
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		props = append(props, objectProp{key: token.(string), value: value})
+	}
+	return props, nil
+}
@@ -0,0 +1,154 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type paymentMethod interface {
+	methodName() string
+}
+
+type cardMethod struct {
+	Kind   string `json:"kind"`
+	Number string `json:"number"`
+}
+
+func (cardMethod) methodName() string { return "card" }
+
+type walletMethod struct {
+	Wallet string `json:"wallet"`
+}
+
+func (*walletMethod) methodName() string { return "wallet" }
+
+type checkoutReq struct {
+	OrderID  int                      `param:"id,path"`
+	Amount   int64                    `json:"amount,string"`
+	Primary  paymentMethod            `json:"primary"`
+	Fallback []paymentMethod          `json:"fallback"`
+	ByRegion map[string]paymentMethod `json:"by_region"`
+}
+
+func TestCallProcessorUnionBody(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	err := router.RegisterUnion((*paymentMethod)(nil), "kind", map[string]interface{}{
+		"card":   cardMethod{},
+		"wallet": &walletMethod{},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	group := router.Group("/orders", "Orders")
+	var got checkoutReq
+	group.POST("/:id/checkout", generator.HandlerParameters{}, func(ctx context.Context, req checkoutReq) (EmptyResp, error) {
+		got = req
+		return EmptyResp{}, nil
+	})
+
+	body := `{"amount": "1500", "primary": {"kind": "card", "number": "4242"},
+		"fallback": [{"kind": "wallet", "wallet": "w1"}], "by_region": {"eu": {"kind": "wallet", "wallet": "w2"}}}`
+	req := httptest.NewRequest(http.MethodPost, "/orders/7/checkout", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 7, got.OrderID)
+	assert.Equal(t, int64(1500), got.Amount)
+	assert.Equal(t, cardMethod{Kind: "card", Number: "4242"}, got.Primary)
+	assert.Equal(t, []paymentMethod{&walletMethod{Wallet: "w1"}}, got.Fallback)
+	assert.Equal(t, map[string]paymentMethod{"eu": &walletMethod{Wallet: "w2"}}, got.ByRegion)
+
+	body = `{"primary": {"kind": "cash"}}`
+	req = httptest.NewRequest(http.MethodPost, "/orders/7/checkout", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	res := ProblemDetails{}
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	assert.NoError(t, err)
+	assert.Contains(t, res.Detail, `unknown kind "cash", expected one of: card, wallet`)
+}
+
+type paymentTree struct {
+	Method   paymentMethod  `json:"method"`
+	Children []*paymentTree `json:"children"`
+	Note     string         `json:"note"`
+}
+
+func TestNewUnionDecoder(t *testing.T) {
+	types := generator.NewTypeRegistry()
+	err := types.RegisterUnion((*paymentMethod)(nil), "kind", map[string]interface{}{
+		"card":   cardMethod{},
+		"wallet": &walletMethod{},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Nil(t, newUnionDecoder(reflect.TypeOf(EmptyResp{}), types))
+
+	decoder := newUnionDecoder(reflect.TypeOf(paymentTree{}), types)
+	if !assert.NotNil(t, decoder) {
+		return
+	}
+	assert.True(t, decoder.unions[reflect.TypeOf(paymentTree{})])
+	assert.True(t, decoder.unions[reflect.TypeOf([]*paymentTree{})])
+	assert.False(t, decoder.unions[reflect.TypeOf("")])
+	assert.False(t, decoder.unions[reflect.TypeOf(cardMethod{})])
+
+	var tree paymentTree
+	err = decoder.decode([]byte(`{"method": {"kind": "card", "number": "1"},
+		"children": [{"method": {"kind": "wallet", "wallet": "w"}, "note": "child"}]}`), reflect.ValueOf(&tree).Elem())
+	assert.NoError(t, err)
+	assert.Equal(t, cardMethod{Kind: "card", Number: "1"}, tree.Method)
+	assert.Equal(t, []*paymentTree{{Method: &walletMethod{Wallet: "w"}, Note: "child"}}, tree.Children)
+}
+
+type notedPayment struct {
+	Method paymentMethod `json:"method"`
+	Note   string        `json:"note"`
+	Title  string        `json:"Note"`
+}
+
+func TestUnionDecoderFieldNames(t *testing.T) {
+	types := generator.NewTypeRegistry()
+	err := types.RegisterUnion((*paymentMethod)(nil), "kind", map[string]interface{}{
+		"card": cardMethod{},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	decoder := newUnionDecoder(reflect.TypeOf(notedPayment{}), types)
+	testCases := []struct {
+		body  string
+		note  string
+		title string
+	}{
+		{body: `{"NOTE": "folded", "Note": "title"}`, note: "folded", title: "title"},
+		{body: `{"Note": "title", "note": "exact", "nOtE": "folded"}`, note: "folded", title: "title"},
+		{body: `{"nOtE": "folded", "note": "exact"}`, note: "exact"},
+	}
+	for _, tc := range testCases {
+		// Properties are matched like by encoding/json
+		plain := struct {
+			Note  string `json:"note"`
+			Title string `json:"Note"`
+		}{}
+		assert.NoError(t, json.Unmarshal([]byte(tc.body), &plain))
+		assert.Equal(t, tc.note, plain.Note, tc.body)
+		assert.Equal(t, tc.title, plain.Title, tc.body)
+		for i := 0; i < 10; i++ {
+			var got notedPayment
+			assert.NoError(t, decoder.decode([]byte(tc.body), reflect.ValueOf(&got).Elem()))
+			assert.Equal(t, notedPayment{Note: tc.note, Title: tc.title}, got, tc.body)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+)
+
+// FieldError describes one request field rejected by validate tag rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError is error of requests rejected by validate tags rules. Rendered by error envelope with 422 status
+type ValidationError struct {
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
+
+func (e ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Errors))
+	for _, fErr := range e.Errors {
+		fields = append(fields, fErr.Field)
+	}
+	return fmt.Sprintf("%s: %s", e.Message, strings.Join(fields, ", "))
+}
+
+var validationErrorType = reflect.TypeOf(ValidationError{})
+
+func newRequestValidator() *validator.Validate {
+	v := validator.New()
+	// Report fields by names used in request: param or json name
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		fInfo := generator.GetFieldInfo(field)
+		if fInfo == nil {
+			return ""
+		}
+		return fInfo.Name
+	})
+	return v
+}
+
+// requestValidator returns validator of router requests. It is created on the first validated request with
+// Optional and Nullable types of all routes, types are never registered in validator in use
+func (s *RouteWrapper) requestValidator() *validator.Validate {
+	if v := s.validator.Load(); v != nil {
+		return v
+	}
+	s.validatorMu.Lock()
+	defer s.validatorMu.Unlock()
+	if v := s.validator.Load(); v != nil {
+		return v
+	}
+	v := newRequestValidator()
+	optionalTypes := make([]interface{}, 0, len(s.optionalTypes))
+	for t := range s.optionalTypes {
+		optionalTypes = append(optionalTypes, reflect.New(t).Elem().Interface())
+	}
+	if len(optionalTypes) > 0 {
+		v.RegisterCustomTypeFunc(optionalValidationValue, optionalTypes...)
+	}
+	s.validator.Store(v)
+	return v
+}
+
+// addOptionalTypes collects Optional and Nullable types of request type. Validator checks their values by field
+// rules, rules are skipped for absent and null values, only required rule rejects them, see missingOptionalErrors.
+// Validator created before route with new types is replaced by the next request
+func (s *RouteWrapper) addOptionalTypes(reqType reflect.Type) {
+	types := make(map[reflect.Type]bool)
+	collectOptionalTypes(reqType, make(map[reflect.Type]bool), types)
+	s.validatorMu.Lock()
+	defer s.validatorMu.Unlock()
+	for t := range types {
+		if !s.optionalTypes[t] {
+			s.optionalTypes[t] = true
+			s.validator.Store(nil)
+		}
+	}
+}
+
+func collectOptionalTypes(t reflect.Type, visited map[reflect.Type]bool, types map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	if elem, _, ok := generator.OptionalElem(t); ok {
+		if t.Kind() != reflect.Ptr {
+			types[t] = true
+		}
+		collectOptionalTypes(elem, visited, types)
+		return
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		collectOptionalTypes(t.Elem(), visited, types)
+	case reflect.Struct:
+		for _, field := range generator.JSONFields(t) {
+			collectOptionalTypes(field.Type, visited, types)
+		}
+	}
+}
+
+// optionalValidationValue returns present value of optional field. Absent and null values are returned as nil,
+// validator reports them with invalid kind and these errors are skipped
+func optionalValidationValue(field reflect.Value) interface{} {
+	if opt, ok := field.Interface().(optionalValue); ok {
+		if value, present := opt.presentValue(); present {
+			return value
+		}
+	}
+	return nil
+}
+
+// validateRequest checks request struct against validate tags. Returns nil or ValidationError
+func (s *RouteWrapper) validateRequest(inputVal reflect.Value) error {
+	var fErrs []FieldError
+	err := s.requestValidator().Struct(inputVal.Interface())
+	var vErrs validator.ValidationErrors
+	if errors.As(err, &vErrs) {
+		for _, vErr := range vErrs {
+			if vErr.Kind() == reflect.Invalid {
+				// Absent or null optional value
+				continue
+			}
+			fieldName := vErr.Namespace()
+			// Remove request struct name from field path
+			if idx := strings.Index(fieldName, "."); idx >= 0 {
+				fieldName = fieldName[idx+1:]
+			}
+			fErrs = append(fErrs, FieldError{
+				Field:   fieldName,
+				Rule:    vErr.Tag(),
+				Param:   vErr.Param(),
+				Message: validationMessage(fieldName, vErr),
+			})
+		}
+	}
+	fErrs = append(fErrs, missingOptionalErrors(inputVal, "")...)
+	if len(fErrs) == 0 {
+		return nil
+	}
+	return ValidationError{
+		Message: "request validation failed",
+		Errors:  fErrs,
+	}
+}
+
+// missingOptionalErrors returns required rule errors of absent and null Optional and Nullable fields
+func missingOptionalErrors(val reflect.Value, fieldName string) []FieldError {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil
+	}
+	if val.CanInterface() {
+		if opt, ok := val.Interface().(optionalValue); ok {
+			value, present := opt.presentValue()
+			if !present {
+				return nil
+			}
+			return missingOptionalErrors(reflect.ValueOf(value), fieldName)
+		}
+	}
+	var fErrs []FieldError
+	switch val.Kind() {
+	case reflect.Struct:
+		for _, field := range generator.JSONFields(val.Type()) {
+			fInfo := generator.GetFieldInfo(field)
+			if fInfo == nil {
+				continue
+			}
+			fVal, err := val.FieldByIndexErr(field.Index)
+			if err != nil {
+				// Nil embedded pointer
+				continue
+			}
+			name := joinFieldName(fieldName, fInfo.Name)
+			if fVal.CanInterface() && hasRequiredRule(field.Tag.Get("validate")) {
+				if opt, ok := fVal.Interface().(optionalValue); ok {
+					if _, present := opt.presentValue(); !present {
+						fErrs = append(fErrs, FieldError{
+							Field:   name,
+							Rule:    "required",
+							Message: fmt.Sprintf("%s is required", name),
+						})
+						continue
+					}
+				}
+			}
+			fErrs = append(fErrs, missingOptionalErrors(fVal, name)...)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			fErrs = append(fErrs, missingOptionalErrors(val.Index(i), fmt.Sprintf("%s[%d]", fieldName, i))...)
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			fErrs = append(fErrs, missingOptionalErrors(iter.Value(), fmt.Sprintf("%s[%v]", fieldName, iter.Key()))...)
+		}
+	}
+	return fErrs
+}
+
+// hasRequiredRule reports if validate tag has required rule for field itself, rules after dive are for elements
+func hasRequiredRule(tag string) bool {
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "dive" {
+			return false
+		}
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+func validationMessage(fieldName string, vErr validator.FieldError) string {
+	switch vErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fieldName)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email", fieldName)
+	case "uuid", "uuid4":
+		return fmt.Sprintf("%s must be a valid UUID", fieldName)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fieldName, vErr.Param())
+	}
+	if vErr.Param() != "" {
+		return fmt.Sprintf("%s must satisfy %s=%s", fieldName, vErr.Tag(), vErr.Param())
+	}
+	return fmt.Sprintf("%s must satisfy %s", fieldName, vErr.Tag())
+}
+
+// validateEnums checks that request values of enum types, documented by generator.EnumValues, are allowed values.
+// Zero values are not checked, they are rejected by required rule. Returns nil or ValidationError
+func validateEnums(inputVal reflect.Value, docs *generator.DocComments) error {
+	fErrs := enumErrors(inputVal, "", docs)
+	if len(fErrs) == 0 {
+		return nil
+	}
+	return ValidationError{
+		Message: "request validation failed",
+		Errors:  fErrs,
+	}
+}
+
+func enumErrors(val reflect.Value, fieldName string, docs *generator.DocComments) []FieldError {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil
+	}
+	if val.CanInterface() {
+		if opt, ok := val.Interface().(optionalValue); ok {
+			value, present := opt.presentValue()
+			if !present {
+				return nil
+			}
+			return enumErrors(reflect.ValueOf(value), fieldName, docs)
+		}
+	}
+	if values := generator.EnumValues(val.Type(), docs); len(values) > 0 {
+		if val.IsZero() || !val.CanInterface() || enumContains(values, val) {
+			return nil
+		}
+		allowed := make([]string, 0, len(values))
+		for _, v := range values {
+			allowed = append(allowed, fmt.Sprint(v))
+		}
+		param := strings.Join(allowed, " ")
+		return []FieldError{{
+			Field:   fieldName,
+			Rule:    "enum",
+			Param:   param,
+			Message: fmt.Sprintf("%s must be one of: %s", fieldName, param),
+		}}
+	}
+	var fErrs []FieldError
+	switch val.Kind() {
+	case reflect.Struct:
+		for _, field := range generator.JSONFields(val.Type()) {
+			fInfo := generator.GetFieldInfo(field)
+			if fInfo == nil {
+				continue
+			}
+			fVal, err := val.FieldByIndexErr(field.Index)
+			if err != nil {
+				// Nil embedded pointer
+				continue
+			}
+			fErrs = append(fErrs, enumErrors(fVal, joinFieldName(fieldName, fInfo.Name), docs)...)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			fErrs = append(fErrs, enumErrors(val.Index(i), fmt.Sprintf("%s[%d]", fieldName, i), docs)...)
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			fErrs = append(fErrs, enumErrors(iter.Value(), fmt.Sprintf("%s[%v]", fieldName, iter.Key()), docs)...)
+		}
+	}
+	return fErrs
+}
+
+func joinFieldName(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// enumContains reports if val equals one of enum values. Values are converted to val type,
+// so constants values and values returned by Enum methods are compared the same way
+func enumContains(values []interface{}, val reflect.Value) bool {
+	for _, v := range values {
+		enumVal := reflect.ValueOf(v)
+		if !sameKindGroup(enumVal.Kind(), val.Kind()) || !enumVal.CanConvert(val.Type()) {
+			continue
+		}
+		if enumVal.Convert(val.Type()).Interface() == val.Interface() {
+			return true
+		}
+	}
+	return false
+}
+
+// sameKindGroup reports if kinds are both strings, both booleans or both numbers.
+// Conversion of integer to string is not value comparison
+func sameKindGroup(a reflect.Kind, b reflect.Kind) bool {
+	group := func(kind reflect.Kind) int {
+		switch kind {
+		case reflect.String:
+			return 1
+		case reflect.Bool:
+			return 2
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return 3
+		}
+		return 0
+	}
+	return group(a) != 0 && group(a) == group(b)
+}
@@ -0,0 +1,188 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type validatedReq struct {
+	Login string `json:"login" validate:"required"`
+	Email string `json:"email" validate:"email"`
+	Age   int    `json:"age" validate:"gte=18"`
+	Limit int    `param:"limit,query" validate:"max=100"`
+}
+
+func TestCallProcessorValidation(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/users", "Users")
+	var called bool
+	group.POST("", generator.HandlerParameters{}, func(ctx context.Context, req validatedReq) (EmptyResp, error) {
+		called = true
+		return EmptyResp{}, nil
+	})
+
+	body := `{"email": "not-email", "age": 10}`
+	req := httptest.NewRequest(http.MethodPost, "/users?limit=200", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.False(t, called)
+	res := ProblemDetails{}
+	err := json.Unmarshal(rec.Body.Bytes(), &res)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Status)
+	rules := make(map[string]string)
+	for _, fErr := range res.Errors {
+		rules[fErr.Field] = fErr.Rule
+	}
+	assert.Equal(t, map[string]string{"login": "required", "email": "email", "age": "gte", "limit": "max"}, rules)
+
+	body = `{"login": "john", "email": "john@example.com", "age": 20}`
+	req = httptest.NewRequest(http.MethodPost, "/users?limit=10", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, called)
+}
+
+type scoreReq struct {
+	Score Optional[float64] `json:"score" validate:"max=10"`
+}
+
+func TestRequestValidatorPerRouter(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	group := router.Group("/users", "Users")
+	group.POST("", generator.HandlerParameters{}, func(ctx context.Context, req validatedReq) (EmptyResp, error) {
+		return EmptyResp{}, nil
+	})
+	post := func(target string, body string) int {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Other router registers routes while requests are validated
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, http.StatusOK, post("/users", `{"login": "john", "email": "john@example.com", "age": 20}`))
+		}()
+	}
+	other := NewRouter(echo.New()).Group("/profiles", "Profiles")
+	other.PATCH("/:id", generator.HandlerParameters{}, func(ctx context.Context, req profilePatchReq) (EmptyResp, error) {
+		return EmptyResp{}, nil
+	})
+	other.PATCH("/:id/title", generator.HandlerParameters{}, func(ctx context.Context, req titlePatchReq) (EmptyResp, error) {
+		return EmptyResp{}, nil
+	})
+	wg.Wait()
+
+	// Optional types of route registered after serving are validated
+	group.POST("/scores", generator.HandlerParameters{}, func(ctx context.Context, req scoreReq) (EmptyResp, error) {
+		return EmptyResp{}, nil
+	})
+	assert.Equal(t, http.StatusOK, post("/users/scores", `{"score": 5}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/users/scores", `{"score": 11}`))
+}
+
+type planTier string
+
+const (
+	planFree planTier = "free"
+	planPro  planTier = "pro"
+)
+
+type planRegion string
+
+func (planRegion) Enum() []interface{} {
+	return []interface{}{"eu", "us"}
+}
+
+type enumReq struct {
+	Region  planRegion   `param:"region,path"`
+	Tier    planTier     `json:"tier"`
+	Regions []planRegion `json:"regions"`
+}
+
+func TestCallProcessorEnumValidation(t *testing.T) {
+	e := echo.New()
+	router := NewRouter(e)
+	router.SetEnumValidation(true)
+	docs := generator.NewDocComments()
+	err := docs.AddPackage("github.com/AlhimicMan/goswag/wrapper", "validation_test.go")
+	if !assert.NoError(t, err) {
+		return
+	}
+	router.SetDocComments(docs)
+	group := router.Group("/plans", "Plans")
+	var called bool
+	group.POST("/:region", generator.HandlerParameters{}, func(ctx context.Context, req enumReq) (EmptyResp, error) {
+		called = true
+		return EmptyResp{}, nil
+	})
+
+	body := `{"tier": "gold", "regions": ["eu", "asia"]}`
+	req := httptest.NewRequest(http.MethodPost, "/plans/mars", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.False(t, called)
+	res := ProblemDetails{}
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	assert.NoError(t, err)
+	params := make(map[string]string)
+	for _, fErr := range res.Errors {
+		assert.Equal(t, "enum", fErr.Rule)
+		params[fErr.Field] = fErr.Param
+	}
+	assert.Equal(t, map[string]string{"region": "eu us", "tier": "free pro", "regions[1]": "eu us"}, params)
+
+	body = `{"tier": "pro", "regions": ["us"]}`
+	req = httptest.NewRequest(http.MethodPost, "/plans/eu", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, called)
+}
+
+func TestValidationResponseSpec(t *testing.T) {
+	router := NewRouter(echo.New())
+	group := router.Group("/users", "Users")
+	group.POST("/", generator.HandlerParameters{}, func(ctx context.Context, req validatedReq) (EmptyResp, error) {
+		return EmptyResp{}, nil
+	})
+	validationRef := func() string {
+		spec, err := router.ExportSpec(FormatJSON)
+		assert.NoError(t, err)
+		doc := struct {
+			Paths map[string]map[string]struct {
+				Responses map[string]struct {
+					Schema struct {
+						Ref string `json:"$ref"`
+					} `json:"schema"`
+				} `json:"responses"`
+			} `json:"paths"`
+		}{}
+		assert.NoError(t, json.Unmarshal(spec, &doc))
+		return doc.Paths["/users"]["post"].Responses["422"].Schema.Ref
+	}
+	assert.Equal(t, "#/definitions/wrapper.ProblemDetails", validationRef())
+	router.SetErrorEnvelope(SimpleEnvelope, SimpleError{})
+	assert.Equal(t, "#/definitions/wrapper.SimpleError", validationRef())
+	router.SetErrorEnvelope(SimpleEnvelope, nil)
+	assert.Equal(t, "", validationRef())
+}
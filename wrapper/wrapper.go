@@ -3,66 +3,337 @@ package wrapper
 import (
 	"encoding/json"
 	"github.com/AlhimicMan/goswag/generator"
+	openapi "github.com/go-openapi/spec"
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/swaggo/swag"
+	"reflect"
+	"sync"
+	"sync/atomic"
 )
 
 type RouteWrapper struct {
-	router *echo.Echo
-	groups []*WrapGroup
+	router        *echo.Echo
+	groups        []*WrapGroup
+	errorEnvelope ErrorEnvelope
+	// errorBodyType is type of bodies built by error envelope
+	errorBodyType reflect.Type
+	errorRegistry *ErrorRegistry
+	// defaultResponses are documented for all routes unless route or group declares response with the same status
+	defaultResponses []generator.ResponseParameters
+	// specVersion is version of generated spec, Swagger 2.0 by default
+	specVersion string
+	specOptions generator.SpecOptions
+	docsCache   specCache
+	// instanceName is name of swag instance router spec is registered under
+	instanceName string
+	swagDoc      *swagDoc
+	namer        generator.DefinitionNamer
+	// types holds schemas of custom types, used for spec generation and parameters binding
+	types          *generator.TypeRegistry
+	requiredPolicy generator.RequiredPolicy
+	int64AsString  bool
+	embedsAllOf    bool
+	docs           *generator.DocComments
+	enumValidation bool
+	// optionalTypes holds Optional and Nullable types of routes requests, registered in validator
+	optionalTypes map[reflect.Type]bool
+	validator     atomic.Pointer[validator.Validate]
+	validatorMu   sync.Mutex
 }
 
-func NewRouter(router *echo.Echo) *RouteWrapper {
-	return &RouteWrapper{
-		router: router,
-		groups: make([]*WrapGroup, 0),
+// NewRouter creates router wrapper. Optional spec options define generated spec metadata like title and version
+func NewRouter(router *echo.Echo, options ...generator.SpecOptions) *RouteWrapper {
+	s := &RouteWrapper{
+		router:        router,
+		groups:        make([]*WrapGroup, 0),
+		instanceName:  swag.Name,
+		types:         generator.NewTypeRegistry(),
+		optionalTypes: make(map[reflect.Type]bool),
 	}
+	if len(options) > 0 {
+		s.specOptions = options[0]
+	}
+	return s
 }
 
 func (s *RouteWrapper) Group(prefix string, tag string, m ...echo.MiddlewareFunc) (g *WrapGroup) {
+	return s.GroupWithTag(prefix, generator.TagInfo{Name: tag}, m...)
+}
+
+// GroupWithTag creates group of routes tagged with tag. Tag description is added to spec
+func (s *RouteWrapper) GroupWithTag(prefix string, tag generator.TagInfo, m ...echo.MiddlewareFunc) *WrapGroup {
 	group := &WrapGroup{
 		echoGroup:      s.router.Group(prefix, m...),
+		routeWrapper:   s,
 		path:           prefix,
 		routesHandlers: make(map[string]generator.RouteInfo),
-		tags:           []string{tag},
+		tags:           []string{tag.Name},
+		tagInfo:        tag,
+		childGroups:    make(map[string]*WrapGroup),
 	}
 	s.groups = append(s.groups, group)
+	s.resetDocsCache()
 	return group
 }
 
 func (s *RouteWrapper) getRoutes() map[string]generator.RouteInfo {
 	routes := make(map[string]generator.RouteInfo)
+	validationErrType := s.validationErrorBodyType()
 	for _, group := range s.groups {
 		groupRoutes := group.getRoutes()
 		for path, routeInfo := range groupRoutes {
+			routeInfo.Parameters.Responses = mergeResponses(routeInfo.Parameters.Responses, s.defaultResponses)
+			if routeInfo.Handler.ValidationErrorType != nil {
+				// Validation errors are rendered by error envelope
+				routeInfo.Handler.ValidationErrorType = validationErrType
+			}
 			routes[path] = routeInfo
 		}
 	}
 	return routes
 }
 
+// SetDefaultResponses sets responses documented for all routes of the router, like 500 error
+func (s *RouteWrapper) SetDefaultResponses(responses ...generator.ResponseParameters) {
+	s.defaultResponses = responses
+	s.resetDocsCache()
+}
+
+// SetSpecOptions sets generated spec metadata like title, version, contact and tags descriptions
+func (s *RouteWrapper) SetSpecOptions(options generator.SpecOptions) {
+	s.specOptions = options
+	s.resetDocsCache()
+}
+
+// specTags returns tags from spec options followed by tags of groups. Groups tags descriptions
+// are used for tags defined in options without description
+func (s *RouteWrapper) specTags() []generator.TagInfo {
+	tags := make([]generator.TagInfo, 0, len(s.specOptions.Tags))
+	tags = append(tags, s.specOptions.Tags...)
+	groupTags := make([]generator.TagInfo, 0)
+	for _, group := range s.groups {
+		groupTags = group.appendTags(groupTags)
+	}
+	for _, groupTag := range groupTags {
+		if groupTag.Name == "" {
+			continue
+		}
+		found := false
+		for i, tag := range tags {
+			if tag.Name != groupTag.Name {
+				continue
+			}
+			found = true
+			if tag.Description == "" {
+				tags[i].Description = groupTag.Description
+			}
+			if tag.ExternalDocs == nil {
+				tags[i].ExternalDocs = groupTag.ExternalDocs
+			}
+		}
+		if !found {
+			tags = append(tags, groupTag)
+		}
+	}
+	return tags
+}
+
+// SetDefinitionNamer sets naming strategy of spec definitions, like generator.FullPathDefinitionName.
+// generator.ShortDefinitionName is used by default
+func (s *RouteWrapper) SetDefinitionNamer(namer generator.DefinitionNamer) {
+	s.namer = namer
+	s.resetDocsCache()
+}
+
+// SetRequiredPolicy sets which definition properties are listed as required, generator.RequiredValidated by default
+func (s *RouteWrapper) SetRequiredPolicy(policy generator.RequiredPolicy) {
+	s.requiredPolicy = policy
+	s.resetDocsCache()
+}
+
+// SetInt64AsString documents 64-bit integers as strings for JavaScript clients, which lose precision of numbers
+// above 2^53. Response and error bodies encode such integers as strings, request bodies accept strings and numbers
+func (s *RouteWrapper) SetInt64AsString(enabled bool) {
+	s.int64AsString = enabled
+	s.resetDocsCache()
+}
+
+// SetEmbedsAsAllOf documents embedded structs as allOf composition instead of flattened fields
+func (s *RouteWrapper) SetEmbedsAsAllOf(enabled bool) {
+	s.embedsAllOf = enabled
+	s.resetDocsCache()
+}
+
+// SetDocComments sets doc comments of handlers, types and fields, loaded by docload.Load, for operations,
+// definitions and properties descriptions. Explicit handler parameters and doc tags take precedence
+func (s *RouteWrapper) SetDocComments(docs *generator.DocComments) {
+	s.docs = docs
+	s.resetDocsCache()
+}
+
+// SetEnumValidation rejects requests with path, query, header and body values of enum types
+// not listed in their enums, see generator.EnumValues. Constants enums are known after SetDocComments
+func (s *RouteWrapper) SetEnumValidation(enabled bool) {
+	s.enumValidation = enabled
+	s.resetDocsCache()
+}
+
+// RegisterTypeSchema sets schema of value type, like decimal.Decimal{}, for definitions, parameters and responses.
+// Types with primitive schemas are bound from request parameters with UnmarshalText or Scan methods.
+// Types must be registered before routes using them
+func (s *RouteWrapper) RegisterTypeSchema(value interface{}, schema openapi.Schema) {
+	s.types.Register(value, schema)
+	s.resetDocsCache()
+}
+
+// RegisterTypeSchemaFunc sets function building schema of value type, used for types of other packages
+// which cannot implement generator.SchemaProvider. Types must be registered before routes using them
+func (s *RouteWrapper) RegisterTypeSchemaFunc(value interface{}, fn generator.SchemaFunc) {
+	s.types.RegisterFunc(value, fn)
+	s.resetDocsCache()
+}
+
+// RegisterUnion sets implementations of interface type, given by pointer like (*Event)(nil), distinguished
+// by discriminator property. Request bodies fields of interface type are decoded to variant chosen by discriminator
+// value and documented as oneOf variants. Unions must be registered before routes using them
+func (s *RouteWrapper) RegisterUnion(iface interface{}, discriminator string, variants map[string]interface{}) error {
+	err := s.types.RegisterUnion(iface, discriminator, variants)
+	s.resetDocsCache()
+	return err
+}
+
+// SetSpecVersion sets version of spec generated by GenerateSwagger: generator.Swagger20 (default),
+// generator.OpenAPI30 or generator.OpenAPI31
+func (s *RouteWrapper) SetSpecVersion(version string) {
+	s.specVersion = version
+	s.resetDocsCache()
+}
+
+// Spec export formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// GenerateSwagger generates JSON spec and registers it in swag registry under router instance name.
+// Returns error if another router registered its spec under the same name
 func (s *RouteWrapper) GenerateSwagger() ([]byte, error) {
-	routes := s.getRoutes()
-	gen := generator.NewSwaggerGenerator()
-	swagSpec, err := gen.EmitOpenAPIDefinition(routes)
+	jsonBytes, err := s.ExportSpec(FormatJSON)
 	if err != nil {
 		return nil, err
 	}
-	swagSpec.Info.Title = "Portal API"
-	jsonBytes, err := json.MarshalIndent(swagSpec, "", "    ")
+	err = s.registerDefinition(string(jsonBytes))
 	if err != nil {
-		return nil, errors.Errorf("Cannot marshal Swagger annotation: %w", err)
+		return nil, err
 	}
-	s.registerDefinition(string(jsonBytes))
 	return jsonBytes, nil
 }
 
-func (s *RouteWrapper) registerDefinition(template string) {
-	var SwaggerInfo = &swag.Spec{
-		InfoInstanceName: "swagger",
-		SwaggerTemplate:  template,
+// ExportSpec generates spec of router spec version in FormatJSON or FormatYAML format.
+// Output is deterministic: object keys and paths are sorted, so it can be committed and diffed
+func (s *RouteWrapper) ExportSpec(format string) ([]byte, error) {
+	spec, err := s.buildSpec()
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err := json.MarshalIndent(spec, "", "    ")
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot marshal Swagger annotation")
+	}
+	switch format {
+	case FormatJSON:
+		return jsonBytes, nil
+	case FormatYAML:
+		yamlBytes, err := generator.JSONToYAML(jsonBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot convert Swagger annotation to YAML")
+		}
+		return yamlBytes, nil
 	}
-	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+	return nil, errors.Errorf("unsupported spec format: %s", format)
+}
 
+// buildSpec generates Swagger 2.0 spec or OpenAPI 3 document depending on router spec version
+func (s *RouteWrapper) buildSpec() (interface{}, error) {
+	routes := s.getRoutes()
+	gen := generator.NewSwaggerGenerator()
+	options := s.specOptions
+	options.Tags = s.specTags()
+	gen.SetSpecOptions(options)
+	gen.SetDefinitionNamer(s.namer)
+	gen.SetTypeRegistry(s.types)
+	gen.SetRequiredPolicy(s.requiredPolicy)
+	gen.SetInt64AsString(s.int64AsString)
+	gen.SetEmbedsAsAllOf(s.embedsAllOf)
+	gen.SetDocComments(s.docs)
+	if s.errorRegistry != nil {
+		gen.AddErrorCodes(s.errorRegistry.ErrorCodes()...)
+	}
+	switch s.specVersion {
+	case "", generator.Swagger20:
+		swagSpec, err := gen.EmitOpenAPIDefinition(routes)
+		if err != nil {
+			return nil, err
+		}
+		return swagSpec, nil
+	}
+	doc, err := gen.EmitOpenAPI3Definition(routes, s.specVersion)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// SchemaGenerator creates generator of definitions of single types, which uses schemas of types registered
+// in router and router definitions names
+func (s *RouteWrapper) SchemaGenerator() *generator.SchemaGenerator {
+	gen := generator.NewSchemaGenerator()
+	gen.SetDefinitionNamer(s.namer)
+	gen.SetTypeRegistry(s.types)
+	return gen
+}
+
+// SetInstanceName sets name of swag instance GenerateSwagger registers router spec under, swag.Name by default.
+// Routers served in one process must use different names. Must be called before GenerateSwagger
+func (s *RouteWrapper) SetInstanceName(name string) {
+	s.instanceName = name
+}
+
+// InstanceName returns name of swag instance of router spec
+func (s *RouteWrapper) InstanceName() string {
+	return s.instanceName
+}
+
+// swagDoc provides latest generated router spec to swag registry
+type swagDoc struct {
+	mu  sync.RWMutex
+	doc string
+}
+
+func (d *swagDoc) ReadDoc() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.doc
+}
+
+func (d *swagDoc) setDoc(doc string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.doc = doc
+}
+
+// registerDefinition registers router spec in swag registry on first call and updates it on next calls
+func (s *RouteWrapper) registerDefinition(doc string) error {
+	if s.swagDoc == nil {
+		name := s.InstanceName()
+		if swag.GetSwagger(name) != nil {
+			return errors.Errorf("swag instance %s is already registered by another router", name)
+		}
+		s.swagDoc = &swagDoc{}
+		swag.Register(name, s.swagDoc)
+	}
+	s.swagDoc.setDoc(doc)
+	return nil
 }
@@ -0,0 +1,107 @@
+package wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/AlhimicMan/goswag/generator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/swaggo/swag"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+type exportUser struct {
+	ID   string `json:"id" param:"id,path"`
+	Name string `json:"name"`
+}
+
+func exportRouter() *RouteWrapper {
+	router := NewRouter(echo.New(), generator.SpecOptions{Title: "Users API"})
+	group := router.Group("/users", "Users")
+	handler := func(ctx context.Context, req exportUser) (exportUser, error) {
+		return req, nil
+	}
+	group.GET("/:id", generator.HandlerParameters{}, handler)
+	group.PUT("/:id", generator.HandlerParameters{}, handler)
+	group.POST("/", generator.HandlerParameters{}, handler)
+	return router
+}
+
+func TestExportSpec(t *testing.T) {
+	for _, version := range []string{generator.Swagger20, generator.OpenAPI30, generator.OpenAPI31} {
+		router := exportRouter()
+		router.SetSpecVersion(version)
+		jsonSpec, err := router.ExportSpec(FormatJSON)
+		assert.NoError(t, err)
+		yamlSpec, err := router.ExportSpec(FormatYAML)
+		assert.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			again, err := router.ExportSpec(FormatJSON)
+			assert.NoError(t, err)
+			assert.Equal(t, string(jsonSpec), string(again))
+		}
+
+		jsonDoc := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal(jsonSpec, &jsonDoc))
+		yamlDoc := make(map[string]interface{})
+		assert.NoError(t, yaml.Unmarshal(yamlSpec, &yamlDoc))
+		yamlJSON, err := json.Marshal(yamlDoc)
+		assert.NoError(t, err)
+		expectedJSON, err := json.Marshal(jsonDoc)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(expectedJSON), string(yamlJSON))
+	}
+
+	_, err := exportRouter().ExportSpec("xml")
+	assert.Error(t, err)
+}
+
+func TestGenerateSwaggerInstances(t *testing.T) {
+	public := exportRouter()
+	public.SetInstanceName("public-test")
+	admin := exportRouter()
+	admin.SetInstanceName("admin-test")
+	admin.SetSpecVersion(generator.OpenAPI30)
+
+	publicSpec, err := public.GenerateSwagger()
+	assert.NoError(t, err)
+	adminSpec, err := admin.GenerateSwagger()
+	assert.NoError(t, err)
+	assert.Equal(t, string(publicSpec), swag.GetSwagger("public-test").ReadDoc())
+	assert.Equal(t, string(adminSpec), swag.GetSwagger("admin-test").ReadDoc())
+
+	// Router can regenerate its spec
+	admin.SetSpecVersion(generator.OpenAPI31)
+	adminSpec, err = admin.GenerateSwagger()
+	assert.NoError(t, err)
+	assert.Contains(t, swag.GetSwagger("admin-test").ReadDoc(), `"openapi": "3.1.0"`)
+	assert.Equal(t, string(adminSpec), swag.GetSwagger("admin-test").ReadDoc())
+
+	other := exportRouter()
+	other.SetInstanceName("public-test")
+	_, err = other.GenerateSwagger()
+	assert.Error(t, err)
+	assert.Equal(t, string(publicSpec), swag.GetSwagger("public-test").ReadDoc())
+}
+
+func TestGenerateSwaggerDefaultInstance(t *testing.T) {
+	first := exportRouter()
+	second := exportRouter()
+	second.SetSpecVersion(generator.OpenAPI30)
+	assert.Equal(t, swag.Name, first.InstanceName())
+	assert.Equal(t, swag.Name, second.InstanceName())
+
+	firstSpec, err := first.GenerateSwagger()
+	assert.NoError(t, err)
+	assert.Equal(t, string(firstSpec), swag.GetSwagger(swag.Name).ReadDoc())
+	_, err = second.GenerateSwagger()
+	assert.Error(t, err)
+	assert.Equal(t, string(firstSpec), swag.GetSwagger(swag.Name).ReadDoc())
+
+	second.SetInstanceName("second-test")
+	secondSpec, err := second.GenerateSwagger()
+	assert.NoError(t, err)
+	assert.Equal(t, string(secondSpec), swag.GetSwagger("second-test").ReadDoc())
+}